@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/pnl"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `usage: daily-pnl [-log-level level] [-log-levels component=level,...] [command]
+
+Without a command the tray application is started.
+
+flags:
+  -log-level        debug, info, warning or error
+  -log-levels       levels of single components (pnl, config, ws, ui),
+                    for example ws=debug,ui=warning
+
+commands:
+  config validate   show where every setting comes from and check it
+  backfill -from YYYY-MM-DD [-to YYYY-MM-DD] [-restart]
+                    rebuild the P&L history from the exchange
+  stats [-from YYYY-MM-DD] [-to YYYY-MM-DD]
+                    show trading statistics, by default for the year to date
+`
+
+// setFlag records a configuration override; tests replace it.
+var setFlag = config.SetFlag
+
+// parseFlags records the flags in front of the command as configuration
+// overrides and returns the remaining arguments. Unknown flags, such as the
+// -psn_ argument macOS passes to applications, are logged and skipped.
+func parseFlags(args []string) []string {
+	flags := flag.NewFlagSet("daily-pnl", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	level := flags.String("log-level", "", "minimum level logged")
+	levels := flags.String("log-levels", "", "levels of single components")
+
+	// Parse stops at the first bad flag, which it has already consumed, so
+	// parsing resumes with the arguments after it.
+	for {
+		err := flags.Parse(args)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(0)
+		}
+
+		logger.Component(logger.ComponentConfig).Warning("ignoring command line flag: %v", err)
+		args = flags.Args()
+	}
+
+	if *level != "" {
+		setFlag("log_level", *level)
+	}
+	if *levels != "" {
+		setFlag("log_levels", *levels)
+	}
+
+	return flags.Args()
+}
+
+// runCommand handles command line invocations and returns the exit code.
+func runCommand(args []string) int {
+	switch {
+	case len(args) == 2 && args[0] == "config" && args[1] == "validate":
+		return validateConfig()
+	case len(args) > 0 && args[0] == "backfill":
+		return backfill(args[1:])
+	case len(args) > 0 && args[0] == "stats":
+		return statistics(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+}
+
+func validateConfig() int {
+	if err := cfg.WriteReport(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write report: %v\n", err)
+		return 1
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stdout, "\nconfiguration is invalid:\n%v\n", err)
+		return 1
+	}
+
+	fmt.Fprintln(os.Stdout, "\nconfiguration is valid")
+	return 0
+}
+
+func backfill(args []string) int {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := flags.String("from", "", "first trading day to fetch (YYYY-MM-DD)")
+	to := flags.String("to", "", "last trading day to fetch, defaults to yesterday")
+	restart := flags.Bool("restart", false, "ignore the progress of an interrupted run")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *from == "" {
+		fmt.Fprintln(os.Stderr, "backfill: -from is required")
+		flags.Usage()
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	filled, err := pnl.RunBackfill(ctx, cfg, *from, *to, *restart, func(progress pnl.BackfillProgress) {
+		fmt.Fprintf(os.Stdout, "[%d/%d] %s\n", progress.Done, progress.Total, progress.Date)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backfill stopped after %d days: %v\n", filled, err)
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "run the same command again to resume")
+		}
+		return 1
+	}
+
+	fmt.Fprintf(os.Stdout, "backfilled %d trading days\n", filled)
+	return 0
+}
+
+func statistics(args []string) int {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	from := flags.String("from", "", "first trading day (YYYY-MM-DD), defaults to the start of the year")
+	to := flags.String("to", "", "last trading day, defaults to today")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	result, err := pnl.LoadStatistics(cfg, *from, *to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stats: %v\n", err)
+		return 1
+	}
+
+	if err := result.WriteReport(os.Stdout, cfg.IsStreamerMode()); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write report: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestParseFlagsSkipsUnknownFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		flags map[string]string
+		rest  []string
+	}{
+		{"known flags", []string{"-log-level", "debug", "stats"},
+			map[string]string{"log_level": "debug"}, []string{"stats"}},
+		{"known flag after an unknown one", []string{"-psn_0_12345", "-log-level", "debug"},
+			map[string]string{"log_level": "debug"}, []string{}},
+		{"unknown flags between known ones", []string{"-log-levels", "ws=debug", "-x", "-psn_0_1", "-log-level", "warning", "config", "validate"},
+			map[string]string{"log_level": "warning", "log_levels": "ws=debug"}, []string{"config", "validate"}},
+		{"missing value", []string{"-log-level"},
+			map[string]string{}, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := make(map[string]string)
+			setFlag = func(name, value string) error {
+				flags[name] = value
+				return nil
+			}
+			t.Cleanup(func() { setFlag = config.SetFlag })
+
+			rest := parseFlags(test.args)
+
+			if !maps.Equal(flags, test.flags) {
+				t.Errorf("flags %v, want %v", flags, test.flags)
+			}
+			if !slices.Equal(rest, test.rest) {
+				t.Errorf("remaining arguments %q, want %q", rest, test.rest)
+			}
+		})
+	}
+}
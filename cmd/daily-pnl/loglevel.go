@@ -0,0 +1,63 @@
+package main
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"strings"
+
+	"github.com/getlantern/systray"
+)
+
+// addLogLevelMenu adds a submenu that changes the log level without a
+// restart. Component levels from the configuration are kept.
+func addLogLevelMenu() {
+	menu := systray.AddMenuItem("Log Level", "Change how much is logged")
+
+	current := logger.GetInstance().Level()
+	items := make([]*systray.MenuItem, len(logger.Levels))
+	for i, level := range logger.Levels {
+		items[i] = menu.AddSubMenuItemCheckbox(levelTitle(level), "Log "+levelTitle(level)+" and above", level == current)
+	}
+
+	for i, item := range items {
+		level := logger.Levels[i]
+
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-item.ClickedCh:
+					setLogLevel(level)
+					for j, other := range items {
+						if j == i {
+							other.Check()
+						} else {
+							other.Uncheck()
+						}
+					}
+				}
+			}
+		}()
+	}
+}
+
+func setLogLevel(level logger.LogLevel) {
+	log := logger.Component(logger.ComponentUI)
+	log.Info("log level changed to %s", level)
+
+	cfg.Mtx.Lock()
+	cfg.LogLevel = strings.ToLower(level.String())
+	cfg.Mtx.Unlock()
+
+	logger.GetInstance().SetLevels(cfg.Levels())
+
+	if err := config.SaveConfig(cfg); err != nil {
+		log.Warning("failed to save log level: %v", err)
+	}
+}
+
+func levelTitle(level logger.LogLevel) string {
+	name := strings.ToLower(level.String())
+	return strings.ToUpper(name[:1]) + name[1:]
+}
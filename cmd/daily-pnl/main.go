@@ -6,32 +6,70 @@ import (
 	"daily-profit-and-loss/internal/config"
 	"daily-profit-and-loss/internal/logger"
 	"daily-profit-and-loss/internal/pnl"
+	"daily-profit-and-loss/internal/ui"
 	"gioui.org/app"
 	"github.com/getlantern/systray"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 var (
 	cfg     *config.Config
 	mStatus *systray.MenuItem
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	tracking sync.WaitGroup
+
+	commandArgs []string
 )
 
 func init() {
+	commandArgs = parseFlags(os.Args[1:])
+
 	log := logger.GetInstance()
-	cfg = config.LoadConfig()
 
-	log.SetLevel(logger.Debug)
+	var err error
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		log.Error("configuration problem: %v", err)
+	}
+
+	cfg.Mtx.Lock()
+	log.SetCapacity(cfg.LogBufferSize)
+	log.SetFormat(cfg.LogFormat)
+	cfg.Mtx.Unlock()
+
+	log.SetRotation(cfg.LogRotation())
+	log.SetLevels(cfg.Levels())
 }
 
 func main() {
+	if len(commandArgs) > 0 {
+		code := runCommand(commandArgs)
+		logger.GetInstance().Close()
+		os.Exit(code)
+	}
+
+	ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-ctx.Done()
+		systray.Quit()
+	}()
+
 	systray.Run(onReady, onExit)
 
 	app.Main()
 }
 
 func onReady() {
-	ctx := context.Background()
-	log := logger.GetInstance()
+	log := logger.Component(logger.ComponentUI)
 
 	systray.SetIcon(app2.Icon)
 
@@ -41,21 +79,98 @@ func onReady() {
 	log.Info("application started")
 
 	mStatus = systray.AddMenuItem("Inactive", "Status")
+	mRefresh := systray.AddMenuItem("Refresh now", "Fetch today's positions from the exchange")
+	mPause := systray.AddMenuItem("Pause tracking", "Stop tracking until resumed")
+	mReconnect := systray.AddMenuItem("Reconnect", "Reconnect to the exchange")
+	mOpenOutput := systray.AddMenuItem("Open output folder", "Open the folder of the P&L output file")
+	mCopy := systray.AddMenuItem("Copy today's P&L", "Copy today's P&L to the clipboard")
 	systray.AddSeparator()
 	mShowConfig := systray.AddMenuItem("Configuration", "Show Configuration")
+	mOverlay := systray.AddMenuItemCheckbox("Overlay", "Show the P&L in a small window on top of others", false)
+	mToday := systray.AddMenuItem("Today", "Show today's P&L timeline")
+	mStatistics := systray.AddMenuItem("Statistics", "Show trading statistics")
+	mBackfill := systray.AddMenuItem("Backfill History", "Rebuild the P&L history from the exchange")
 	mLogs := systray.AddMenuItem("Logs", "Show application logs")
+	addLogLevelMenu()
 	mInfo := systray.AddMenuItem("Info", "Show application info")
 	mQuit := systray.AddMenuItem("Quit", "Quit the application")
 
-	go pnl.RunPnl(ctx, cfg, mStatus)
+	go config.Watch(ctx, cfg)
+
+	// Without always-on-top and placement the overlay is an ordinary window,
+	// so it is only offered where both work.
+	if ui.PlacementSupported {
+		cfg.Mtx.Lock()
+		showOverlay := cfg.Overlay
+		cfg.Mtx.Unlock()
+		if showOverlay {
+			openOverlay(mOverlay)
+		}
+	} else {
+		mOverlay.Hide()
+	}
+
+	commands := make(chan pnl.Command, 8)
+	// send never blocks the menu loop; a command is dropped while the
+	// tracker is too busy to keep up.
+	send := func(command pnl.Command) bool {
+		select {
+		case commands <- command:
+			return true
+		default:
+			log.Warning("pnl tracking is busy, ignoring command %d", command)
+			return false
+		}
+	}
 
+	tracking.Add(1)
 	go func() {
+		defer tracking.Done()
+		pnl.RunPnl(ctx, cfg, mStatus, commands)
+	}()
+
+	go func() {
+		paused := false
+
 		for {
 			select {
+			case <-ctx.Done():
+				return
+			case <-mRefresh.ClickedCh:
+				log.Info("refresh menu item clicked")
+				send(pnl.CommandRefresh)
+			case <-mPause.ClickedCh:
+				if !paused {
+					log.Info("pause menu item clicked")
+					if send(pnl.CommandPause) {
+						paused = true
+						mPause.SetTitle("Resume tracking")
+						mRefresh.Disable()
+					}
+				} else {
+					log.Info("resume menu item clicked")
+					if send(pnl.CommandResume) {
+						paused = false
+						mPause.SetTitle("Pause tracking")
+						mRefresh.Enable()
+					}
+				}
+			case <-mReconnect.ClickedCh:
+				log.Info("reconnect menu item clicked")
+				if send(pnl.CommandReconnect) && paused {
+					paused = false
+					mPause.SetTitle("Pause tracking")
+					mRefresh.Enable()
+				}
+			case <-mOpenOutput.ClickedCh:
+				log.Info("open output folder menu item clicked")
+				pnl.OpenOutputFolder(cfg, log)
+			case <-mCopy.ClickedCh:
+				log.Info("copy menu item clicked")
+				pnl.CopyTodayPnl(cfg, log)
 			case <-mQuit.ClickedCh:
 				log.Info("application shutdown requested")
-				systray.Quit()
-				os.Exit(0)
+				cancel()
 				return
 			case <-mInfo.ClickedCh:
 				log.Info("info menu item clicked")
@@ -66,12 +181,56 @@ func onReady() {
 
 				go func() {
 					if err := pnl.RunInfoWindow(w, log); err != nil {
-						log.Error(err.Error())
+						log.WithError(err).Error("info window failed")
+					}
+				}()
+			case <-mOverlay.ClickedCh:
+				log.Info("overlay menu item clicked")
+				toggleOverlay(mOverlay)
+			case <-mToday.ClickedCh:
+				log.Info("today menu item clicked")
+				w := new(app.Window)
+				w.Option(app.Title("Today"))
+				w.Option(app.Size(400, 450))
+
+				go func() {
+					if err := pnl.RunStatusWindow(w, cfg, log); err != nil {
+						log.WithError(err).Error("today window failed")
+					}
+				}()
+			case <-mStatistics.ClickedCh:
+				log.Info("statistics menu item clicked")
+				w := new(app.Window)
+				w.Option(app.Title("Statistics"))
+				w.Option(app.Size(450, 600))
+
+				go func() {
+					if err := pnl.RunStatisticsWindow(w, cfg, log); err != nil {
+						log.WithError(err).Error("statistics window failed")
+					}
+				}()
+			case <-mBackfill.ClickedCh:
+				log.Info("backfill menu item clicked")
+				w := new(app.Window)
+				w.Option(app.Title("Backfill History"))
+				w.Option(app.Size(350, 450))
+
+				go func() {
+					if err := pnl.RunBackfillWindow(w, cfg, log); err != nil {
+						log.WithError(err).Error("backfill window failed")
 					}
 				}()
 			case <-mLogs.ClickedCh:
 				log.Info("logs menu item clicked")
-				pnl.OpenLogFile(log)
+				w := new(app.Window)
+				w.Option(app.Title("Logs"))
+				w.Option(app.Size(800, 600))
+
+				go func() {
+					if err := pnl.RunLogWindow(w, cfg, log); err != nil {
+						log.WithError(err).Error("log window failed")
+					}
+				}()
 			case <-mShowConfig.ClickedCh:
 				log.Info("show UI menu item clicked")
 				w := new(app.Window)
@@ -80,7 +239,7 @@ func onReady() {
 
 				go func() {
 					if err := config.RunConfigWindow(w, cfg, log); err != nil {
-						log.Error(err.Error())
+						log.WithError(err).Error("configuration window failed")
 					}
 				}()
 			}
@@ -89,5 +248,25 @@ func onReady() {
 }
 
 func onExit() {
+	log := logger.GetInstance()
+	log.Info("application shutting down")
+
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		tracking.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		log.Warning("pnl tracking did not stop within %s", shutdownTimeout)
+	}
+
+	log.Info("application stopped")
+	log.Close()
+
 	os.Exit(0)
 }
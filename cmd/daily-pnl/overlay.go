@@ -0,0 +1,82 @@
+package main
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/pnl"
+	"sync"
+
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/unit"
+	"github.com/getlantern/systray"
+)
+
+// overlay is the open overlay window, nil while it is hidden.
+var overlay struct {
+	mtx    sync.Mutex
+	window *app.Window
+}
+
+// toggleOverlay opens the overlay when it is hidden and closes it otherwise,
+// and remembers the choice for the next start.
+func toggleOverlay(item *systray.MenuItem) {
+	overlay.mtx.Lock()
+	window := overlay.window
+	overlay.window = nil
+	overlay.mtx.Unlock()
+
+	if window != nil {
+		window.Perform(system.ActionClose)
+		setOverlayEnabled(false)
+		return
+	}
+
+	openOverlay(item)
+	setOverlayEnabled(true)
+}
+
+func openOverlay(item *systray.MenuItem) {
+	log := logger.Component(logger.ComponentUI)
+
+	w := new(app.Window)
+	w.Option(app.Title("Daily P&L"))
+	w.Option(app.Size(unit.Dp(230), unit.Dp(110)))
+	w.Option(app.Decorated(false))
+
+	overlay.mtx.Lock()
+	overlay.window = w
+	overlay.mtx.Unlock()
+	item.Check()
+
+	go func() {
+		if err := pnl.RunOverlayWindow(w, cfg, log); err != nil {
+			log.WithError(err).Error("overlay window failed")
+		}
+
+		overlay.mtx.Lock()
+		current := overlay.window == w
+		if current {
+			overlay.window = nil
+		}
+		overlay.mtx.Unlock()
+		item.Uncheck()
+
+		// A window closed through the window manager stays closed at the
+		// next start, like one closed from the menu. Quitting the
+		// application keeps the setting.
+		if current && ctx.Err() == nil {
+			setOverlayEnabled(false)
+		}
+	}()
+}
+
+func setOverlayEnabled(enabled bool) {
+	cfg.Mtx.Lock()
+	cfg.Overlay = enabled
+	cfg.Mtx.Unlock()
+
+	if err := config.SaveConfig(cfg); err != nil {
+		logger.Component(logger.ComponentUI).Warning("failed to save overlay setting: %v", err)
+	}
+}
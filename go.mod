@@ -6,13 +6,18 @@ require (
 	gioui.org v0.8.0
 	github.com/gen2brain/beeep v0.0.0-20240516210008-9c006672e7f4
 	github.com/getlantern/systray v1.2.2
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/sirupsen/logrus v1.9.3
 	github.com/tradingiq/bitunix-client v0.1.0
+	github.com/zalando/go-keyring v0.2.6
+	golang.org/x/image v0.24.0
 )
 
 require (
+	al.essio.dev/pkg/shellescape v1.5.1 // indirect
 	gioui.org/shader v1.0.8 // indirect
 	github.com/coder/websocket v1.8.13 // indirect
+	github.com/danieljoos/wincred v1.2.2 // indirect
 	github.com/getlantern/context v0.0.0-20190109183933-c447772a6520 // indirect
 	github.com/getlantern/errors v1.0.1 // indirect
 	github.com/getlantern/golog v0.0.0-20230503153817-8e72de7e0a65 // indirect
@@ -22,7 +27,6 @@ require (
 	github.com/go-stack/stack v1.8.0 // indirect
 	github.com/go-text/typesetting v0.2.1 // indirect
 	github.com/go-toast/toast v0.0.0-20190211030409-01e6764cf0a4 // indirect
-	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/nu7hatch/gouuid v0.0.0-20131221200532-179d4d0c4d8d // indirect
 	github.com/oxtoacart/bpool v0.0.0-20190530202638-03653db5a59c // indirect
 	github.com/stretchr/testify v1.10.0 // indirect
@@ -32,7 +36,6 @@ require (
 	go.uber.org/zap v1.19.1 // indirect
 	golang.org/x/exp v0.0.0-20240707233637-46b078467d37 // indirect
 	golang.org/x/exp/shiny v0.0.0-20240707233637-46b078467d37 // indirect
-	golang.org/x/image v0.24.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
 )
@@ -22,3 +22,15 @@ func GetConfigPath() string {
 
 	return filepath.Join(appDir, "config.json")
 }
+
+func GetVaultPath() string {
+	return filepath.Join(GetDirectory(), "secrets.vault")
+}
+
+func GetHistoryPath() string {
+	return filepath.Join(GetDirectory(), "history.json")
+}
+
+func GetBackfillStatePath() string {
+	return filepath.Join(GetDirectory(), "backfill.json")
+}
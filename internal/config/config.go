@@ -2,68 +2,476 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
 	pnlapp "daily-profit-and-loss/internal/app"
 	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/picker"
+	"daily-profit-and-loss/internal/secrets"
+	"daily-profit-and-loss/internal/tradingday"
 	"daily-profit-and-loss/internal/ui"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gioui.org/app"
 	"gioui.org/layout"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"github.com/tradingiq/bitunix-client/bitunix"
-	"github.com/tradingiq/bitunix-client/model"
 	"os"
-	"os/exec"
-	"runtime"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	apiKeyField    = "api_key"
+	secretKeyField = "secret_key"
+
+	DefaultReportingCurrency = "USDT"
+	DefaultTimezone          = "Europe/Berlin"
+	DefaultWeekStart         = "monday"
+	DefaultTrayIcon          = "tint"
+	DefaultLogLevel          = "info"
+	DefaultOutputTemplate    = `{{printf "%.2f" .Today}} {{.Currency}}`
+	DefaultTitleTemplate     = `{{printf "%+.2f" .Today}} {{.Currency}} | {{.Trades}} trades`
+	// StreamerTitleTemplate replaces the tray title template in streamer
+	// mode, so no absolute amount is shown.
+	StreamerTitleTemplate = `{{if .HasPercent}}{{printf "%+.2f" .TodayPercent}}% | {{end}}{{.Trades}} trades`
+)
+
+// WindowPosition is the top left corner of a window in screen pixels.
+type WindowPosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
 type Config struct {
-	ApiKey            string        `json:"api_key"`
-	SecretKey         string        `json:"secret_key"`
-	ProfitAndLossFile string        `json:"profit_and_loss_file"`
-	Mtx               sync.Mutex    `json:"-"`
-	Changed           chan struct{} `json:"-"`
+	Version           int    `json:"version"`
+	ApiKey            string `json:"api_key,omitempty"`
+	SecretKey         string `json:"secret_key,omitempty"`
+	ProfitAndLossFile string `json:"profit_and_loss_file"`
+	StreamerMode      bool   `json:"streamer_mode"`
+
+	// ReportingCurrency is the coin all P&L is converted to for display.
+	ReportingCurrency string `json:"reporting_currency"`
+
+	// Timezone, DayStartHour and WeekStart define the trading day and week
+	// boundaries used for resets and rollups.
+	Timezone     string `json:"timezone"`
+	DayStartHour int    `json:"day_start_hour"`
+	WeekStart    string `json:"week_start"`
+
+	// OutputTemplate is a text/template rendering the P&L output file.
+	OutputTemplate string `json:"output_template"`
+	// TitleTemplate renders the tray title with the same fields.
+	TitleTemplate string `json:"tray_title_template"`
+
+	// MaxDailyLoss and MaxDailyLossPercent trigger an alert once the
+	// realized loss of the day reaches them; zero disables the limit.
+	MaxDailyLoss        float64 `json:"max_daily_loss"`
+	MaxDailyLossPercent float64 `json:"max_daily_loss_percent"`
+
+	// GiveBackAlert triggers an alert once the P&L falls this amount below
+	// the day's peak; zero disables it.
+	GiveBackAlert float64 `json:"give_back_alert"`
+
+	// TrayIcon selects how the tray icon reflects the P&L: static, tint,
+	// number or ring.
+	TrayIcon string `json:"tray_icon"`
+
+	// Overlay shows the always-on-top P&L window; OverlayPosition is where it
+	// was last placed.
+	Overlay         bool            `json:"overlay"`
+	OverlayPosition *WindowPosition `json:"overlay_position,omitempty"`
+
+	// LogBufferSize is the number of log entries kept in memory for the log
+	// viewer.
+	LogBufferSize int `json:"log_buffer_size"`
+	// LogMaxSizeMB starts a new log file within the day once the current one
+	// reaches this size; LogRetentionDays deletes older log files. Zero
+	// disables either.
+	LogMaxSizeMB     int `json:"log_max_size_mb"`
+	LogRetentionDays int `json:"log_retention_days"`
+	// LogFormat is the format of the log files: text or json.
+	LogFormat string `json:"log_format"`
+	// LogLevel is the minimum level logged; LogLevels overrides it for
+	// single components (pnl, config, ws or ui).
+	LogLevel  string            `json:"log_level"`
+	LogLevels map[string]string `json:"log_levels,omitempty"`
+
+	Mtx     sync.Mutex    `json:"-"`
+	Changed chan struct{} `json:"-"`
+
+	secrets  secrets.Store
+	origins  map[string]Origin
+	problems []error
+	loadErr  error
+	fileHash [sha256.Size]byte
+	// fileValues are the settings as last read from or written to
+	// config.json, so overridden settings can be written back unchanged.
+	fileValues map[string]json.RawMessage
 }
 
-func LoadConfig() *Config {
-	log := logger.GetInstance()
+// openSecrets opens the credential store; tests replace it.
+var openSecrets = secrets.Open
+
+// LoadConfig always returns a usable config. When config.json cannot be read
+// or parsed the returned error describes why, defaults are used instead and
+// SaveConfig refuses to overwrite the file until the problem is fixed.
+func LoadConfig() (*Config, error) {
+	log := logger.Component(logger.ComponentConfig)
 
 	config := &Config{
 		Changed: make(chan struct{}),
 	}
+	config.applyDefaults()
+
+	store, err := openSecrets(pnlapp.GetVaultPath())
+	if err != nil {
+		log.Warning("credentials will be kept in the config file: %v", err)
+	} else {
+		log.Debug("using %s for credentials", store.Name())
+		config.secrets = store
+	}
 
 	configPath := pnlapp.GetConfigPath()
 	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Info("no config file found at %s, using defaults (this is normal for first run)", configPath)
+		config.resolveSources(config.loadSecrets())
+		return config, nil
+	}
 	if err != nil {
-		log.Error("could not read config file (this is normal for first run):", err)
-		return config
+		log.Error("could not read config file: %v", err)
+		config.loadErr = fmt.Errorf("could not read %s: %w", configPath, err)
+		config.resolveSources(config.loadSecrets())
+		return config, config.loadErr
 	}
 
-	err = json.Unmarshal(data, &config)
+	config.fileHash = sha256.Sum256(data)
+
+	version, err := config.decode(data)
 	if err != nil {
-		log.Error("could not parse config file:", err)
-		return &Config{}
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || errors.Is(err, ErrUnsupportedVersion) {
+			log.Error("could not parse config file: %v", err)
+
+			config = &Config{
+				Changed: config.Changed,
+				secrets: config.secrets,
+			}
+			config.applyDefaults()
+			config.loadErr = fmt.Errorf("could not parse %s: %w", configPath, err)
+			config.resolveSources(config.loadSecrets())
+			return config, config.loadErr
+		}
+
+		log.Warning("config file contains invalid settings: %v", err)
+	}
+
+	migrate := version < CurrentVersion
+	if migrate {
+		backupPath, err := backup(configPath, version)
+		if err != nil {
+			log.Error("could not back up config file before migration: %v", err)
+			config.loadErr = fmt.Errorf("could not back up %s before migration: %w", configPath, err)
+			migrate = false
+		} else {
+			log.Info("migrating config file from version %d to %d, backup written to %s", version, CurrentVersion, backupPath)
+		}
+	}
+
+	// The credentials must be known before the file is rewritten, otherwise
+	// saving would delete them from the secret store.
+	fromSecretStore := false
+	if config.ApiKey != "" || config.SecretKey != "" {
+		fromSecretStore = config.migrateSecrets()
+		migrate = migrate && !fromSecretStore
+	} else {
+		fromSecretStore = config.loadSecrets()
+	}
+
+	if migrate {
+		if err := SaveConfig(config); err != nil {
+			log.Error("could not write migrated config file: %v", err)
+		}
+	}
+
+	config.resolveSources(fromSecretStore)
+
+	return config, config.loadErr
+}
+
+func (c *Config) loadSecrets() bool {
+	if c.secrets == nil {
+		return false
+	}
+	log := logger.Component(logger.ComponentConfig)
+
+	apiKey, err := c.secrets.Get(apiKeyField)
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		log.Error("could not read api key from %s: %v", c.secrets.Name(), err)
+	}
+
+	secretKey, err := c.secrets.Get(secretKeyField)
+	if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+		log.Error("could not read secret key from %s: %v", c.secrets.Name(), err)
+	}
+
+	c.Mtx.Lock()
+	c.ApiKey = apiKey
+	c.SecretKey = secretKey
+	c.Mtx.Unlock()
+
+	return true
+}
+
+// migrateSecrets moves plaintext credentials found in config.json into the
+// secret store and rewrites the file without them.
+func (c *Config) migrateSecrets() bool {
+	if c.secrets == nil {
+		logger.Component(logger.ComponentConfig).Warning("config file contains plaintext credentials and no secure store is available")
+		return false
+	}
+	log := logger.Component(logger.ComponentConfig)
+
+	if err := SaveConfig(c); err != nil {
+		log.Error("could not migrate credentials to %s: %v", c.secrets.Name(), err)
+		return false
+	}
+
+	log.Info("moved plaintext credentials from config file to %s", c.secrets.Name())
+	return true
+}
+
+func (c *Config) storeSecrets(skip map[string]bool) error {
+	values := map[string]string{
+		apiKeyField:    c.ApiKey,
+		secretKeyField: c.SecretKey,
+	}
+
+	for key, value := range values {
+		if skip[key] {
+			continue
+		}
+
+		var err error
+		if value == "" {
+			err = c.secrets.Delete(key)
+		} else {
+			err = c.secrets.Set(key, value)
+		}
+		if err != nil {
+			return fmt.Errorf("error storing %s in %s: %w", key, c.secrets.Name(), err)
+		}
 	}
 
-	return config
+	return nil
 }
 
 func SaveConfig(config *Config) error {
-	log := logger.GetInstance()
+	log := logger.Component(logger.ComponentConfig)
 	config.Mtx.Lock()
 	defer config.Mtx.Unlock()
+
+	config.protectSecrets()
+
+	if config.loadErr != nil {
+		return fmt.Errorf("refusing to overwrite config file: %w", config.loadErr)
+	}
+
+	config.Version = CurrentVersion
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		log.Error("error marshaling config:", err)
+		log.Error("error marshaling config: %v", err)
+		return fmt.Errorf("error marshaling config: %w", err)
+	}
+
+	external := config.fromEnvironment()
+	omit := make(map[string]bool)
+	if config.secrets != nil {
+		if err := config.storeSecrets(external); err != nil {
+			log.Error("error storing credentials: %v", err)
+			return err
+		}
+
+		omit[apiKeyField] = true
+		omit[secretKeyField] = true
+	}
+
+	values, err := withFileValues(data, external, omit, config.fileValues)
+	if err != nil {
+		log.Error("error marshaling config: %v", err)
+		return fmt.Errorf("error marshaling config: %w", err)
+	}
+	data, err = json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		log.Error("error marshaling config: %v", err)
 		return fmt.Errorf("error marshaling config: %w", err)
 	}
 
 	configPath := pnlapp.GetConfigPath()
-	return os.WriteFile(configPath, data, 0600)
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return err
+	}
+
+	config.fileHash = sha256.Sum256(data)
+	config.fileValues = values
+	return nil
+}
+
+// IsStreamerMode reports whether balances and absolute P&L must be hidden.
+func (c *Config) IsStreamerMode() bool {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	return c.StreamerMode
+}
+
+// Currency returns the reporting currency in upper case.
+func (c *Config) Currency() string {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	if c.ReportingCurrency == "" {
+		return DefaultReportingCurrency
+	}
+
+	return strings.ToUpper(c.ReportingCurrency)
+}
+
+// Calendar returns the configured trading day boundaries, falling back to
+// the defaults when they are invalid.
+func (c *Config) Calendar() tradingday.Calendar {
+	c.Mtx.Lock()
+	timezone, startHour, weekStart := c.Timezone, c.DayStartHour, c.WeekStart
+	c.Mtx.Unlock()
+
+	calendar, err := tradingday.New(timezone, startHour, weekStart)
+	if err != nil {
+		logger.Component(logger.ComponentConfig).Warning("invalid trading day settings, using defaults: %v", err)
+		calendar, _ = tradingday.New(DefaultTimezone, 0, DefaultWeekStart)
+	}
+
+	return calendar
+}
+
+// Template returns the output file template.
+func (c *Config) Template() string {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	if c.OutputTemplate == "" {
+		return DefaultOutputTemplate
+	}
+
+	return c.OutputTemplate
+}
+
+// TrayTitleTemplate returns the tray title template for the current mode.
+func (c *Config) TrayTitleTemplate() string {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	switch {
+	case c.StreamerMode:
+		return StreamerTitleTemplate
+	case c.TitleTemplate == "":
+		return DefaultTitleTemplate
+	}
+
+	return c.TitleTemplate
+}
+
+// LossLimits returns the absolute and the percentage daily loss limit.
+func (c *Config) LossLimits() (float64, float64) {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	return c.MaxDailyLoss, c.MaxDailyLossPercent
+}
+
+// GiveBack returns the amount below the day's peak that triggers an alert.
+func (c *Config) GiveBack() float64 {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	return c.GiveBackAlert
+}
+
+// LogRotation returns when log files are rotated and how long they are kept.
+// Files are named after the trading day.
+func (c *Config) LogRotation() logger.Rotation {
+	calendar := c.Calendar()
+
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	return logger.Rotation{
+		Date:      calendar.Date,
+		MaxSize:   int64(c.LogMaxSizeMB) << 20,
+		Retention: time.Duration(c.LogRetentionDays) * 24 * time.Hour,
+	}
+}
+
+// Levels returns the configured log level and the levels of components that
+// have their own. Invalid levels are reported by Validate and ignored here.
+func (c *Config) Levels() (logger.LogLevel, map[string]logger.LogLevel) {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	level, _ := logger.ParseLevel(c.LogLevel)
+
+	components := make(map[string]logger.LogLevel, len(c.LogLevels))
+	for component, name := range c.LogLevels {
+		if l, err := logger.ParseLevel(name); err == nil {
+			components[component] = l
+		}
+	}
+
+	return level, components
+}
+
+// IconStyle returns the configured tray icon style.
+func (c *Config) IconStyle() string {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	return c.TrayIcon
+}
+
+// withFileValues decodes data and replaces the external fields with the value
+// they have in config.json, dropping those the file does not set, and removes
+// the omitted fields.
+func withFileValues(data []byte, external, omit map[string]bool, file map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	for field := range external {
+		if raw, ok := file[field]; ok {
+			values[field] = raw
+		} else {
+			delete(values, field)
+		}
+	}
+	for field := range omit {
+		delete(values, field)
+	}
+
+	return values, nil
+}
+
+const maskRune = '•'
+
+type connectionResult struct {
+	apiKey     string
+	secretKey  string
+	folderPath string
+	save       bool
+	err        error
 }
 
 func RunConfigWindow(w *app.Window, config *Config, log *logger.Logger) error {
@@ -74,6 +482,11 @@ func RunConfigWindow(w *app.Window, config *Config, log *logger.Logger) error {
 		secretKeyInput  widget.Editor
 		folderPathInput widget.Editor
 		selectFolderBtn widget.Clickable
+		selectFileBtn   widget.Clickable
+		streamerMode    widget.Bool
+		revealApiKey    widget.Clickable
+		revealSecretKey widget.Clickable
+		testButton      widget.Clickable
 		saveButton      widget.Clickable
 		closeButton     widget.Clickable
 	)
@@ -82,153 +495,229 @@ func RunConfigWindow(w *app.Window, config *Config, log *logger.Logger) error {
 	secretKeyInput.SingleLine = true
 	folderPathInput.SingleLine = true
 
+	var storeWarning string
+	config.Mtx.Lock()
+	if config.secrets == nil {
+		storeWarning = fmt.Sprintf("No secure credential store is available, so the keys are saved in plain text in config.json. Set %s to keep them in an encrypted vault instead.", secrets.PassphraseEnv)
+	}
 	apiKeyInput.SetText(config.ApiKey)
 	secretKeyInput.SetText(config.SecretKey)
 	folderPathInput.SetText(config.ProfitAndLossFile)
+	streamerMode.Value = config.StreamerMode
+	config.Mtx.Unlock()
+
+	var (
+		status          string
+		reminder        string
+		apiKeyError     string
+		secretKeyError  string
+		folderPathError string
+		busy            bool
+	)
+
+	results := make(chan connectionResult, 1)
+
+	validateCredentials := func() bool {
+		apiKeyError, secretKeyError = "", ""
+		if strings.TrimSpace(apiKeyInput.Text()) == "" {
+			apiKeyError = "API key is required"
+		}
+		if strings.TrimSpace(secretKeyInput.Text()) == "" {
+			secretKeyError = "Secret key is required"
+		}
 
-	status := ""
+		return apiKeyError == "" && secretKeyError == ""
+	}
+
+	validateFolder := func() bool {
+		folderPathError = ""
+
+		folderPath := strings.TrimSpace(folderPathInput.Text())
+		if folderPath == "" {
+			return true
+		}
+
+		if _, err := os.Stat(folderPath); os.IsNotExist(err) {
+			if _, err := os.Stat(filepath.Dir(folderPath)); err != nil {
+				folderPathError = "Folder does not exist"
+			}
+		} else if err != nil {
+			folderPathError = fmt.Sprintf("Cannot access folder: %v", err)
+		}
+
+		return folderPathError == ""
+	}
+
+	testConnection := func(save bool) {
+		request := connectionResult{
+			apiKey:     strings.TrimSpace(apiKeyInput.Text()),
+			secretKey:  strings.TrimSpace(secretKeyInput.Text()),
+			folderPath: strings.TrimSpace(folderPathInput.Text()),
+			save:       save,
+		}
+
+		busy = true
+		status = "Testing connection..."
+		reminder = ""
+
+		go func() {
+			result := request
+
+			apiClient, err := bitunix.NewApiClient(result.apiKey, result.secretKey)
+			if err != nil {
+				log.Error("failed to create API client: %v", err)
+			} else {
+				ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+				err = VerifyReadAccess(ctx, apiClient)
+				cancel()
+			}
+			result.err = err
+
+			results <- result
+			w.Invalidate()
+		}()
+	}
+
+	save := func(result connectionResult) {
+		config.Mtx.Lock()
+		config.ApiKey = result.apiKey
+		config.SecretKey = result.secretKey
+		config.ProfitAndLossFile = result.folderPath
+		config.StreamerMode = streamerMode.Value
+		config.Mtx.Unlock()
+
+		if err := SaveConfig(config); err != nil {
+			status = fmt.Sprintf("Error saving config: %v", err)
+			return
+		}
+
+		status = "Configuration saved successfully!"
+		go func() { config.Changed <- struct{}{} }()
+	}
 
 	configHandler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
 		th := theme.(*material.Theme)
 
 		ui.CloseButtonHandler(&closeButton, gtx, closeRequested)
 
-		if selectFolderBtn.Clicked(gtx) {
+		select {
+		case result := <-results:
+			busy = false
+
+			if result.err != nil {
+				status = fmt.Sprintf("Connection failed: %v", result.err)
+				apiKeyError = "Check the API key"
+				secretKeyError = "Check the secret key"
+				break
+			}
+
+			reminder = KeyReminder
 
+			if result.save {
+				save(result)
+			} else {
+				status = "Connection successful"
+			}
+		default:
+		}
+
+		pickOutput := func(mode picker.Mode) {
 			go func() {
-				selectedPath := ShowFolderPicker(log)
+				selectedPath := ShowOutputPicker(log, mode)
 				if selectedPath != "" {
 
 					folderPathInput.SetText(selectedPath)
-					status = "Folder selected: " + selectedPath
+					status = "Output selected: " + selectedPath
 				} else {
-					status = "Folder selection canceled or failed"
+					status = "Selection canceled or failed"
 				}
+				w.Invalidate()
 			}()
 		}
 
-		if saveButton.Clicked(gtx) {
-			apiKey := apiKeyInput.Text()
-			secretKey := secretKeyInput.Text()
-			folderPath := folderPathInput.Text()
+		if selectFolderBtn.Clicked(gtx) {
+			pickOutput(picker.ModeFolder)
+		}
+
+		if selectFileBtn.Clicked(gtx) {
+			pickOutput(picker.ModeFile)
+		}
 
-			apiClient, err := bitunix.NewApiClient(apiKey, secretKey)
-			if err != nil {
-				log.Error("failed to create API client: %v", err)
+		if testButton.Clicked(gtx) && !busy {
+			if validateCredentials() {
+				testConnection(false)
 			}
-			ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-			defer cancel()
-
-			if _, err := apiClient.GetAccountBalance(ctx, model.AccountBalanceParams{MarginCoin: model.ParseMarginCoin("usdt")}); err != nil {
-				status = fmt.Sprintf("Credentials are invalid: %v", err)
-			} else {
+		}
 
-				if folderPath != "" {
-					_, err := os.Stat(folderPath)
-					if os.IsNotExist(err) {
-						status = fmt.Sprintf("Folder path does not exist: %v", err)
-					} else if err != nil {
-						status = fmt.Sprintf("Error checking folder path: %v", err)
-					} else {
-						config.Mtx.Lock()
-						config.ApiKey = apiKey
-						config.SecretKey = secretKey
-						config.ProfitAndLossFile = folderPath
-						config.Mtx.Unlock()
-
-						err := SaveConfig(config)
-						if err != nil {
-							status = fmt.Sprintf("Error saving config: %v", err)
-						} else {
-							status = "Configuration saved successfully!"
-
-							go func() { config.Changed <- struct{}{} }()
-						}
-					}
-				}
+		if saveButton.Clicked(gtx) && !busy {
+			credentialsValid := validateCredentials()
+			folderValid := validateFolder()
+			if credentialsValid && folderValid {
+				testConnection(true)
 			}
 		}
 
+		apiKeyInput.Mask = maskRune
+		if revealApiKey.Pressed() {
+			apiKeyInput.Mask = 0
+		}
+		secretKeyInput.Mask = maskRune
+		if revealSecretKey.Pressed() {
+			secretKeyInput.Mask = 0
+		}
+
 		apiKeyField := ui.NewLabeledInput(th, "API Key:", "Enter API Key", &apiKeyInput)
+		apiKeyField.Error = apiKeyError
 		secretKeyField := ui.NewLabeledInput(th, "Secret Key:", "Enter Secret Key", &secretKeyInput)
-		folderPathField := ui.NewLabeledInput(th, "Folder Path:", "Enter Folder Path", &folderPathInput)
-
-		folderPathWithButton := func(gtx layout.Context) layout.Dimensions {
-			return layout.Flex{
-				Axis:      layout.Horizontal,
-				Spacing:   layout.SpaceBetween,
-				Alignment: layout.Middle,
-			}.Layout(gtx,
-				layout.Flexed(0.8, func(gtx layout.Context) layout.Dimensions {
-					return folderPathField.Layout(gtx)
-				}),
-				layout.Flexed(0.2, func(gtx layout.Context) layout.Dimensions {
-					return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-						selectBtn := material.Button(th, &selectFolderBtn, "Select")
-						return selectBtn.Layout(gtx)
-					})
-				}),
-			)
-		}
+		secretKeyField.Error = secretKeyError
+		folderPathField := ui.NewLabeledInput(th, "Output Folder or File (optional):", "Enter Folder or File Path", &folderPathInput)
+		folderPathField.Error = folderPathError
 
 		return ui.VerticalLayout(gtx,
 			ui.Title(th, "BitUnix Configuration"),
-			apiKeyField.Layout,
-			secretKeyField.Layout,
-			folderPathWithButton,
+			ui.WarningText(th, storeWarning),
+			ui.InputWithButton(th, apiKeyField, &revealApiKey, "Hold to show"),
+			ui.InputWithButton(th, secretKeyField, &revealSecretKey, "Hold to show"),
+			ui.InputWithButton(th, folderPathField, &selectFolderBtn, "Folder"),
+			ui.CenteredButton(th, &selectFileBtn, "Choose Output File"),
+			ui.CheckBox(th, &streamerMode, "Streamer mode (show percentages only)"),
+			ui.CenteredButton(th, &testButton, "Test Connection"),
 			ui.CenteredButton(th, &saveButton, "Save Configuration"),
 			ui.CenteredButton(th, &closeButton, "Close"),
+			ui.Spinner(th, busy),
 			ui.StatusText(th, status),
+			ui.WarningText(th, reminder),
 		)
 	}
 
 	return ui.RunWindow(w, configHandler, th)
 }
 
-func ShowFolderPicker(log *logger.Logger) string {
-	var command *exec.Cmd
-	var output []byte
-	var err error
-
-	switch runtime.GOOS {
-	case "windows":
-		script := `
-Add-Type -AssemblyName System.Windows.Forms
-$folderBrowser = New-Object System.Windows.Forms.FolderBrowserDialog
-$folderBrowser.Description = "Select a folder"
-$folderBrowser.RootFolder = [System.Environment+SpecialFolder]::MyComputer
-if ($folderBrowser.ShowDialog() -eq [System.Windows.Forms.DialogResult]::OK) {
-    Write-Output $folderBrowser.SelectedPath
-}
-`
-		command = exec.Command("powershell", "-Command", script)
-
-	default:
-		log.Error("Unsupported platform for folder picker:", runtime.GOOS)
-		return ""
+// ShowOutputPicker asks for the P&L output location, either a folder (the
+// file pnl.txt is created inside) or a specific text file.
+func ShowOutputPicker(log *logger.Logger, mode picker.Mode) string {
+	opts := picker.Options{
+		Title: "Select the P&L output folder",
+		Mode:  mode,
+	}
+	if mode == picker.ModeFile {
+		opts.Title = "Select the P&L output file"
+		opts.Filters = []picker.Filter{{Name: "Text files", Extensions: []string{"txt"}}}
+		opts.DefaultName = "pnl.txt"
 	}
 
-	output, err = command.Output()
+	path, err := picker.New().Pick(opts)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-
-			return ""
+		if !errors.Is(err, picker.ErrCanceled) {
+			log.Error("error showing picker: %v", err)
 		}
-		log.Error("error showing folder picker:", err)
 		return ""
 	}
 
-	folderPath := strings.TrimSpace(string(output))
-
-	if folderPath == "" {
-		return ""
-	}
-
-	_, err = os.Stat(folderPath)
-	if err != nil {
-		log.Error("error validating selected folder:", err)
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		log.Error("error validating selected path: %v", err)
 		return ""
 	}
 
-	return folderPath
+	return path
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	pnlapp "daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/secrets"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+type memoryStore map[string]string
+
+func (m memoryStore) Name() string { return "memory store" }
+
+func (m memoryStore) Get(key string) (string, error) {
+	value, ok := m[key]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (m memoryStore) Set(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func (m memoryStore) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+// useTestHome points the application directory at a temporary directory and
+// the secret store at store.
+func useTestHome(t *testing.T, store secrets.Store) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	openSecrets = func(string) (secrets.Store, error) { return store, nil }
+	t.Cleanup(func() { openSecrets = secrets.Open })
+
+	if err := os.MkdirAll(pnlapp.GetDirectory(), 0700); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigMigrationKeepsStoredCredentials(t *testing.T) {
+	store := memoryStore{apiKeyField: "stored-api-key", secretKeyField: "stored-secret-key"}
+	useTestHome(t, store)
+
+	// Files written before versioning have no version field and their
+	// credentials already live in the secret store.
+	configPath := pnlapp.GetConfigPath()
+	if err := os.WriteFile(configPath, []byte(`{"profit_and_loss_file": "", "reporting_currency": "EUR"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if store[apiKeyField] != "stored-api-key" || store[secretKeyField] != "stored-secret-key" {
+		t.Fatalf("credentials in the store changed to %v", store)
+	}
+	if cfg.ApiKey != "stored-api-key" || cfg.SecretKey != "stored-secret-key" {
+		t.Errorf("loaded credentials %q/%q, want the stored ones", cfg.ApiKey, cfg.SecretKey)
+	}
+	if cfg.ReportingCurrency != "EUR" {
+		t.Errorf("reporting currency %q, want EUR", cfg.ReportingCurrency)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if string(doc[versionField]) != "1" {
+		t.Errorf("migrated file has version %s, want 1", doc[versionField])
+	}
+	if _, ok := doc[apiKeyField]; ok {
+		t.Error("migrated file contains the api key")
+	}
+
+	if _, err := os.Stat(configPath + ".v0.bak"); err != nil {
+		t.Errorf("no backup of the old file: %v", err)
+	}
+}
+
+func TestLoadConfigMovesPlaintextCredentials(t *testing.T) {
+	store := memoryStore{}
+	useTestHome(t, store)
+
+	configPath := pnlapp.GetConfigPath()
+	if err := os.WriteFile(configPath, []byte(`{"api_key": "plain-api-key", "secret_key": "plain-secret-key"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if store[apiKeyField] != "plain-api-key" || store[secretKeyField] != "plain-secret-key" {
+		t.Errorf("store holds %v, want the credentials from the file", store)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc[apiKeyField]; ok {
+		t.Error("file still contains the api key")
+	}
+}
+
+func TestLoadConfigReportsFailedBackup(t *testing.T) {
+	useTestHome(t, memoryStore{})
+
+	configPath := pnlapp.GetConfigPath()
+	if err := os.WriteFile(configPath, []byte(`{"reporting_currency": "EUR"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in place of the backup makes writing it fail.
+	if err := os.Mkdir(configPath+".v0.bak", 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig()
+	if err == nil {
+		t.Fatal("LoadConfig succeeded although the backup failed")
+	}
+	if cfg.ReportingCurrency != "EUR" {
+		t.Errorf("reporting currency %q, want EUR", cfg.ReportingCurrency)
+	}
+	if err := SaveConfig(cfg); err == nil {
+		t.Error("SaveConfig overwrote the file that could not be backed up")
+	}
+}
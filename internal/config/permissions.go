@@ -0,0 +1,21 @@
+package config
+
+import (
+	"context"
+
+	"github.com/tradingiq/bitunix-client/bitunix"
+	"github.com/tradingiq/bitunix-client/model"
+)
+
+// KeyReminder is shown after a successful connection test. BitUnix does not
+// report the permissions of a key, so the tracker cannot check for trade or
+// withdrawal rights itself.
+const KeyReminder = "BitUnix does not report key permissions. Make sure this key is read-only and has no withdrawal rights."
+
+// VerifyReadAccess checks that the key behind apiClient can read the account
+// by fetching its balance.
+func VerifyReadAccess(ctx context.Context, apiClient bitunix.ApiClient) error {
+	// Any margin coin will do, the request only proves the key can read.
+	_, err := apiClient.GetAccountBalance(ctx, model.AccountBalanceParams{MarginCoin: model.ParseMarginCoin("usdt")})
+	return err
+}
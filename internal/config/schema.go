@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/tradingday"
+	"daily-profit-and-loss/internal/trayicon"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// CurrentVersion is the schema version written by this build. Bump it and add
+// an entry to migrations whenever the layout of config.json changes.
+const CurrentVersion = 1
+
+const versionField = "version"
+
+// ErrUnsupportedVersion is returned for config files written by a newer build.
+var ErrUnsupportedVersion = errors.New("unsupported config version")
+
+// FieldError names the setting a problem belongs to.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldError(field, format string, args ...interface{}) error {
+	return &FieldError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// migrations upgrade the raw JSON document from the keyed version to the
+// next one.
+var migrations = map[int]func(doc map[string]json.RawMessage) error{
+	// Files written before versioning carry no version field; their layout is
+	// identical to version 1.
+	0: func(doc map[string]json.RawMessage) error {
+		return nil
+	},
+}
+
+func (c *Config) applyDefaults() {
+	c.Version = CurrentVersion
+	c.ProfitAndLossFile = ""
+	c.ReportingCurrency = DefaultReportingCurrency
+	c.Timezone = DefaultTimezone
+	c.DayStartHour = 0
+	c.WeekStart = DefaultWeekStart
+	c.OutputTemplate = DefaultOutputTemplate
+	c.TitleTemplate = DefaultTitleTemplate
+	c.TrayIcon = DefaultTrayIcon
+	c.LogBufferSize = logger.DefaultCapacity
+	c.LogMaxSizeMB = logger.DefaultMaxSize >> 20
+	c.LogRetentionDays = int(logger.DefaultRetention / (24 * time.Hour))
+	c.LogFormat = logger.FormatText
+	c.LogLevel = DefaultLogLevel
+}
+
+// decode parses data into c, migrating older schema versions first. It
+// returns the version the document was stored with.
+func (c *Config) decode(data []byte) (int, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("config file is not valid JSON: %w", err)
+	}
+
+	version := 0
+	if raw, ok := doc[versionField]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return 0, fieldError(versionField, "must be a whole number")
+		}
+	}
+
+	if version > CurrentVersion {
+		return version, &FieldError{Field: versionField, Err: fmt.Errorf("%w %d, this build supports up to %d", ErrUnsupportedVersion, version, CurrentVersion)}
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return version, fmt.Errorf("no migration from config version %d", v)
+		}
+		if err := migrate(doc); err != nil {
+			return version, fmt.Errorf("migrating config from version %d: %w", v, err)
+		}
+	}
+
+	delete(doc, versionField)
+	c.fileValues = doc
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return version, err
+	}
+
+	c.applyDefaults()
+
+	decoder := json.NewDecoder(bytes.NewReader(migrated))
+	if err := decoder.Decode(c); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return version, fieldError(typeErr.Field, "expected %s but found %s", typeErr.Type, typeErr.Value)
+		}
+		return version, err
+	}
+	c.Version = CurrentVersion
+
+	return version, errors.Join(c.validate()...)
+}
+
+// validate checks the values of all settings and returns one error per bad
+// field.
+func (c *Config) validate() []error {
+	var problems []error
+
+	if c.ReportingCurrency == "" || strings.ContainsFunc(c.ReportingCurrency, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		problems = append(problems, fieldError("reporting_currency", "must be a coin symbol such as USDT"))
+	}
+
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		problems = append(problems, fieldError("timezone", "unknown timezone %q", c.Timezone))
+	}
+	if c.DayStartHour < 0 || c.DayStartHour > 23 {
+		problems = append(problems, fieldError("day_start_hour", "must be between 0 and 23"))
+	}
+	if _, err := tradingday.ParseWeekday(c.WeekStart); err != nil {
+		problems = append(problems, fieldError("week_start", "must be a weekday such as monday"))
+	}
+	if _, err := template.New("output").Parse(c.OutputTemplate); err != nil {
+		problems = append(problems, fieldError("output_template", "%v", err))
+	}
+	if _, err := template.New("title").Parse(c.TitleTemplate); err != nil {
+		problems = append(problems, fieldError("tray_title_template", "%v", err))
+	}
+
+	if c.MaxDailyLoss < 0 {
+		problems = append(problems, fieldError("max_daily_loss", "must not be negative"))
+	}
+	if c.MaxDailyLossPercent < 0 || c.MaxDailyLossPercent > 100 {
+		problems = append(problems, fieldError("max_daily_loss_percent", "must be between 0 and 100"))
+	}
+	if c.GiveBackAlert < 0 {
+		problems = append(problems, fieldError("give_back_alert", "must not be negative"))
+	}
+	if c.LogBufferSize < 1 {
+		problems = append(problems, fieldError("log_buffer_size", "must be at least 1"))
+	}
+	if c.LogMaxSizeMB < 0 {
+		problems = append(problems, fieldError("log_max_size_mb", "must not be negative"))
+	}
+	if c.LogRetentionDays < 0 {
+		problems = append(problems, fieldError("log_retention_days", "must not be negative"))
+	}
+	if c.LogFormat != logger.FormatText && c.LogFormat != logger.FormatJSON {
+		problems = append(problems, fieldError("log_format", "must be text or json"))
+	}
+	if _, err := logger.ParseLevel(c.LogLevel); err != nil {
+		problems = append(problems, fieldError("log_level", "must be debug, info, warning or error"))
+	}
+	for component, level := range c.LogLevels {
+		if !slices.Contains(logger.Components, component) {
+			problems = append(problems, fieldError("log_levels", "unknown component %q, must be one of %s", component, strings.Join(logger.Components, ", ")))
+		} else if _, err := logger.ParseLevel(level); err != nil {
+			problems = append(problems, fieldError("log_levels", "level of %s must be debug, info, warning or error", component))
+		}
+	}
+	if !trayicon.ValidStyle(c.TrayIcon) {
+		problems = append(problems, fieldError("tray_icon", "must be static, tint, number or ring"))
+	}
+
+	if c.ProfitAndLossFile != "" {
+		if _, err := os.Stat(c.ProfitAndLossFile); err != nil {
+			if _, err := os.Stat(filepath.Dir(c.ProfitAndLossFile)); err != nil {
+				problems = append(problems, fieldError("profit_and_loss_file", "directory does not exist"))
+			}
+		}
+	}
+
+	return problems
+}
+
+// backup copies the config file aside before it is rewritten in a newer
+// schema version.
+func backup(path string, version int) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
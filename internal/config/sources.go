@@ -0,0 +1,400 @@
+package config
+
+import (
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/secrets"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const envPrefix = "DAILY_PNL_"
+
+type Source string
+
+const (
+	SourceDefault     Source = "default"
+	SourceConfigFile  Source = "config file"
+	SourceSecretStore Source = "secret store"
+	SourceEnv         Source = "environment"
+	SourceSecretFile  Source = "secret file"
+	SourceFlag        Source = "command line"
+)
+
+// Origin records where the effective value of a setting came from.
+type Origin struct {
+	Source Source
+	Detail string
+}
+
+func (o Origin) String() string {
+	if o.Detail == "" {
+		return string(o.Source)
+	}
+
+	return fmt.Sprintf("%s: %s", o.Source, o.Detail)
+}
+
+// external reports whether the value was injected from outside the config
+// file; such values are never written back to disk.
+func (o Origin) external() bool {
+	return o.Source == SourceEnv || o.Source == SourceSecretFile || o.Source == SourceFlag
+}
+
+// setting describes a configuration value that can be overridden from the
+// environment, either directly (DAILY_PNL_<NAME>) or through a file whose
+// path is given in DAILY_PNL_<NAME>_FILE.
+type setting struct {
+	name   string
+	secret bool
+	// restart marks settings tracking captures when it starts, so an
+	// external change restarts it.
+	restart bool
+	get     func(c *Config) string
+	set     func(c *Config, value string) error
+}
+
+// flagValues are settings given on the command line with SetFlag.
+var flagValues = make(map[string]string)
+
+// SetFlag overrides the named setting with a command line value. It takes
+// precedence over the environment and must be called before LoadConfig.
+func SetFlag(name, value string) error {
+	for _, s := range settings {
+		if s.name == name {
+			flagValues[name] = value
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown setting %q", name)
+}
+
+var settings = []setting{
+	{
+		name:    apiKeyField,
+		secret:  true,
+		restart: true,
+		get:     func(c *Config) string { return c.ApiKey },
+		set:     func(c *Config, value string) error { c.ApiKey = value; return nil },
+	},
+	{
+		name:    secretKeyField,
+		secret:  true,
+		restart: true,
+		get:     func(c *Config) string { return c.SecretKey },
+		set:     func(c *Config, value string) error { c.SecretKey = value; return nil },
+	},
+	{
+		name: "profit_and_loss_file",
+		get:  func(c *Config) string { return c.ProfitAndLossFile },
+		set:  func(c *Config, value string) error { c.ProfitAndLossFile = value; return nil },
+	},
+	{
+		name: "streamer_mode",
+		get:  func(c *Config) string { return strconv.FormatBool(c.StreamerMode) },
+		set: func(c *Config, value string) (err error) {
+			c.StreamerMode, err = strconv.ParseBool(value)
+			return err
+		},
+	},
+	{
+		name:    "reporting_currency",
+		restart: true,
+		get:     func(c *Config) string { return c.ReportingCurrency },
+		set:     func(c *Config, value string) error { c.ReportingCurrency = strings.ToUpper(value); return nil },
+	},
+	{
+		name:    "timezone",
+		restart: true,
+		get:     func(c *Config) string { return c.Timezone },
+		set:     func(c *Config, value string) error { c.Timezone = value; return nil },
+	},
+	{
+		name:    "day_start_hour",
+		restart: true,
+		get:     func(c *Config) string { return strconv.Itoa(c.DayStartHour) },
+		set: func(c *Config, value string) (err error) {
+			c.DayStartHour, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		name:    "week_start",
+		restart: true,
+		get:     func(c *Config) string { return c.WeekStart },
+		set:     func(c *Config, value string) error { c.WeekStart = value; return nil },
+	},
+	{
+		name: "output_template",
+		get:  func(c *Config) string { return c.OutputTemplate },
+		set:  func(c *Config, value string) error { c.OutputTemplate = value; return nil },
+	},
+	{
+		name: "tray_title_template",
+		get:  func(c *Config) string { return c.TitleTemplate },
+		set:  func(c *Config, value string) error { c.TitleTemplate = value; return nil },
+	},
+	{
+		name: "max_daily_loss",
+		get:  func(c *Config) string { return formatFloat(c.MaxDailyLoss) },
+		set: func(c *Config, value string) (err error) {
+			c.MaxDailyLoss, err = strconv.ParseFloat(value, 64)
+			return err
+		},
+	},
+	{
+		name: "max_daily_loss_percent",
+		get:  func(c *Config) string { return formatFloat(c.MaxDailyLossPercent) },
+		set: func(c *Config, value string) (err error) {
+			c.MaxDailyLossPercent, err = strconv.ParseFloat(value, 64)
+			return err
+		},
+	},
+	{
+		name: "give_back_alert",
+		get:  func(c *Config) string { return formatFloat(c.GiveBackAlert) },
+		set: func(c *Config, value string) (err error) {
+			c.GiveBackAlert, err = strconv.ParseFloat(value, 64)
+			return err
+		},
+	},
+	{
+		name: "tray_icon",
+		get:  func(c *Config) string { return c.TrayIcon },
+		set:  func(c *Config, value string) error { c.TrayIcon = strings.ToLower(value); return nil },
+	},
+	{
+		name: "log_buffer_size",
+		get:  func(c *Config) string { return strconv.Itoa(c.LogBufferSize) },
+		set: func(c *Config, value string) (err error) {
+			c.LogBufferSize, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		name: "log_max_size_mb",
+		get:  func(c *Config) string { return strconv.Itoa(c.LogMaxSizeMB) },
+		set: func(c *Config, value string) (err error) {
+			c.LogMaxSizeMB, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		name: "log_retention_days",
+		get:  func(c *Config) string { return strconv.Itoa(c.LogRetentionDays) },
+		set: func(c *Config, value string) (err error) {
+			c.LogRetentionDays, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		name: "log_format",
+		get:  func(c *Config) string { return c.LogFormat },
+		set:  func(c *Config, value string) error { c.LogFormat = strings.ToLower(value); return nil },
+	},
+	{
+		name: "log_level",
+		get:  func(c *Config) string { return c.LogLevel },
+		set:  func(c *Config, value string) error { c.LogLevel = strings.ToLower(value); return nil },
+	},
+	{
+		name: "log_levels",
+		get:  func(c *Config) string { return formatLevels(c.LogLevels) },
+		set: func(c *Config, value string) (err error) {
+			c.LogLevels, err = parseLevels(value)
+			return err
+		},
+	},
+	{
+		name: "overlay",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Overlay) },
+		set: func(c *Config, value string) (err error) {
+			c.Overlay, err = strconv.ParseBool(value)
+			return err
+		},
+	},
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatLevels writes component levels as ws=debug,ui=warning.
+func formatLevels(levels map[string]string) string {
+	pairs := make([]string, 0, len(levels))
+	for _, component := range slices.Sorted(maps.Keys(levels)) {
+		pairs = append(pairs, component+"="+levels[component])
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func parseLevels(value string) (map[string]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	levels := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		component, level, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected component=level, got %q", pair)
+		}
+		levels[strings.ToLower(strings.TrimSpace(component))] = strings.ToLower(strings.TrimSpace(level))
+	}
+
+	return levels, nil
+}
+
+func (s setting) envName() string {
+	return envPrefix + strings.ToUpper(s.name)
+}
+
+// resolveSources records the origin of every value read from config.json or
+// the secret store and then applies command line and environment overrides.
+// Precedence, from highest to lowest: command line, DAILY_PNL_<NAME>,
+// DAILY_PNL_<NAME>_FILE, secret store, config.json, built-in default.
+func (c *Config) resolveSources(fromSecretStore bool) {
+	log := logger.Component(logger.ComponentConfig)
+
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	c.origins = make(map[string]Origin, len(settings))
+	c.problems = nil
+
+	defaults := &Config{}
+	defaults.applyDefaults()
+
+	for _, s := range settings {
+		origin := Origin{Source: SourceDefault}
+		if s.get(c) != s.get(defaults) {
+			origin.Source = SourceConfigFile
+			if s.secret && fromSecretStore {
+				origin.Source = SourceSecretStore
+			}
+		}
+
+		value, envOrigin, err := lookupOverride(s)
+		if err != nil {
+			log.Error("could not resolve %s from environment: %v", s.name, err)
+			c.problems = append(c.problems, &FieldError{Field: s.name, Err: err})
+		} else if envOrigin != nil {
+			if err := s.set(c, value); err != nil {
+				c.problems = append(c.problems, &FieldError{Field: s.name, Err: err})
+			} else {
+				origin = *envOrigin
+				log.Debug("%s taken from %s", s.name, origin)
+			}
+		}
+
+		c.origins[s.name] = origin
+	}
+
+	c.protectSecrets()
+}
+
+// protectSecrets keeps the credentials out of the logs. The caller must hold
+// c.Mtx.
+func (c *Config) protectSecrets() {
+	logger.GetInstance().AddSecrets(c.ApiKey, c.SecretKey, os.Getenv(secrets.PassphraseEnv))
+}
+
+func lookupOverride(s setting) (string, *Origin, error) {
+	if value, ok := flagValues[s.name]; ok {
+		return value, &Origin{Source: SourceFlag, Detail: "-" + strings.ReplaceAll(s.name, "_", "-")}, nil
+	}
+
+	if value, ok := os.LookupEnv(s.envName()); ok {
+		return value, &Origin{Source: SourceEnv, Detail: s.envName()}, nil
+	}
+
+	path, ok := os.LookupEnv(s.envName() + "_FILE")
+	if !ok || path == "" {
+		return "", nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read %s_FILE: %w", s.envName(), err)
+	}
+
+	return strings.TrimSpace(string(data)), &Origin{Source: SourceSecretFile, Detail: path}, nil
+}
+
+// Origin returns where the effective value of the named setting came from.
+func (c *Config) Origin(name string) Origin {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	if origin, ok := c.origins[name]; ok {
+		return origin
+	}
+
+	return Origin{Source: SourceDefault}
+}
+
+// fromEnvironment lists the settings whose value was injected from the
+// environment or the command line; SaveConfig keeps their file values.
+func (c *Config) fromEnvironment() map[string]bool {
+	names := make(map[string]bool)
+	for name, origin := range c.origins {
+		if origin.external() {
+			names[name] = true
+		}
+	}
+
+	return names
+}
+
+// Validate reports problems that prevent the tracker from running.
+func (c *Config) Validate() error {
+	c.Mtx.Lock()
+	defer c.Mtx.Unlock()
+
+	var problems []error
+	if c.loadErr != nil {
+		problems = append(problems, c.loadErr)
+	}
+	problems = append(problems, c.problems...)
+	problems = append(problems, c.validate()...)
+
+	if c.ApiKey == "" {
+		problems = append(problems, fieldError(apiKeyField, "not set"))
+	}
+	if c.SecretKey == "" {
+		problems = append(problems, fieldError(secretKeyField, "not set"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// WriteReport prints every setting together with its origin. Secret values
+// are never printed, only whether they are set.
+func (c *Config) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	for _, s := range settings {
+		c.Mtx.Lock()
+		value := s.get(c)
+		c.Mtx.Unlock()
+
+		switch {
+		case value == "":
+			value = "(not set)"
+		case s.secret:
+			value = "(set)"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.name, value, c.Origin(s.name))
+	}
+
+	return tw.Flush()
+}
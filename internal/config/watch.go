@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	pnlapp "daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/logger"
+	"errors"
+	"fmt"
+	"github.com/gen2brain/beeep"
+	"os"
+	"time"
+)
+
+const watchInterval = 2 * time.Second
+
+// Watch polls config.json for edits made outside the application and applies
+// them to config until ctx is cancelled.
+func Watch(ctx context.Context, config *Config) {
+	log := logger.Component(logger.ComponentConfig)
+	configPath := pnlapp.GetConfigPath()
+
+	var lastModTime time.Time
+	var lastSize int64 = -1
+	if info, err := os.Stat(configPath); err == nil {
+		lastModTime, lastSize = info.ModTime(), info.Size()
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	log.Debug("watching %s for changes", configPath)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(configPath)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Equal(lastModTime) && info.Size() == lastSize {
+			continue
+		}
+		lastModTime, lastSize = info.ModTime(), info.Size()
+
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			log.Warning("could not read changed config file: %v", err)
+			continue
+		}
+
+		if err := config.reload(data); err != nil {
+			log.Error("ignoring invalid config file edit: %v", err)
+
+			if err := beeep.Notify("TradingIQ PNL Tracker", fmt.Sprintf("Configuration change not applied: %v", err), "assets/information.png"); err != nil {
+				log.Warning("Could not notify about invalid configuration: %v", err)
+			}
+		}
+	}
+}
+
+// reload applies an externally edited config file. Settings that only affect
+// presentation are applied in place; changes of credentials and of settings
+// tracking depends on, such as the reporting currency or the trading day,
+// restart tracking.
+func (c *Config) reload(data []byte) error {
+	log := logger.Component(logger.ComponentConfig)
+
+	hash := sha256.Sum256(data)
+
+	c.Mtx.Lock()
+	unchanged := hash == c.fileHash
+	c.Mtx.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	edited := &Config{}
+	if _, err := edited.decode(data); err != nil {
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || errors.Is(err, ErrUnsupportedVersion) {
+			c.Mtx.Lock()
+			c.loadErr = fmt.Errorf("could not parse %s: %w", pnlapp.GetConfigPath(), err)
+			c.Mtx.Unlock()
+		}
+
+		return err
+	}
+
+	c.Mtx.Lock()
+	c.fileHash = hash
+	c.loadErr = nil
+	c.fileValues = edited.fileValues
+
+	restart := false
+	for _, s := range settings {
+		if origin, ok := c.origins[s.name]; ok && origin.external() {
+			continue
+		}
+
+		value := s.get(edited)
+		if s.secret && value == "" {
+			continue
+		}
+		if value == s.get(c) {
+			continue
+		}
+
+		if err := s.set(c, value); err != nil {
+			c.Mtx.Unlock()
+			return &FieldError{Field: s.name, Err: err}
+		}
+
+		if s.restart {
+			restart = true
+		}
+		log.Info("applied external change of %s", s.name)
+	}
+	hasPlaintextSecrets := edited.ApiKey != "" || edited.SecretKey != ""
+	c.protectSecrets()
+	bufferSize, format := c.LogBufferSize, c.LogFormat
+	c.Mtx.Unlock()
+
+	log.SetCapacity(bufferSize)
+	log.SetFormat(format)
+	log.SetLevels(c.Levels())
+	log.SetRotation(c.LogRotation())
+
+	if hasPlaintextSecrets && c.secrets != nil {
+		c.migrateSecrets()
+	}
+
+	if restart {
+		log.Info("settings changed on disk, restarting pnl tracking")
+		go func() { c.Changed <- struct{}{} }()
+	}
+
+	return nil
+}
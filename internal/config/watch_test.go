@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReloadRestartsTracking(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		restart bool
+	}{
+		{"presentation", `{"streamer_mode": true}`, false},
+		{"reporting currency", `{"reporting_currency": "EUR"}`, true},
+		{"timezone", `{"timezone": "America/New_York"}`, true},
+		{"day start hour", `{"day_start_hour": 8}`, true},
+		{"week start", `{"week_start": "sunday"}`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTestHome(t, memoryStore{})
+
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := cfg.reload([]byte(test.file)); err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+
+			restarted := false
+			select {
+			case <-cfg.Changed:
+				restarted = true
+			case <-time.After(100 * time.Millisecond):
+			}
+			if restarted != test.restart {
+				t.Errorf("restarted %v, want %v", restarted, test.restart)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package diagnostics collects what is needed to look into a bug report.
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// WriteBundle writes a zip file for bug reports: the in-memory log
+// entries, the log files, the configuration report and system information.
+// The configuration report never contains secrets and log entries and files
+// are redacted.
+func WriteBundle(path string, cfg *config.Config, log *logger.Logger) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	bundle := zip.NewWriter(file)
+
+	var entries bytes.Buffer
+	for _, entry := range log.Entries() {
+		fmt.Fprintln(&entries, entry)
+	}
+	if err := addToBundle(bundle, "entries.log", &entries); err != nil {
+		return err
+	}
+
+	var report bytes.Buffer
+	if err := cfg.WriteReport(&report); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(&report, "\nconfiguration is invalid:\n%v\n", err)
+	}
+	if err := addToBundle(bundle, "config.txt", &report); err != nil {
+		return err
+	}
+
+	var system bytes.Buffer
+	fmt.Fprintf(&system, "time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&system, "os: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&system, "go: %s\n", runtime.Version())
+	if err := addToBundle(bundle, "system.txt", &system); err != nil {
+		return err
+	}
+
+	logFiles, err := filepath.Glob(filepath.Join(app.GetLogDirectory(), "*.log"))
+	if err != nil {
+		return err
+	}
+	compressed, err := filepath.Glob(filepath.Join(app.GetLogDirectory(), "*.log.gz"))
+	if err != nil {
+		return err
+	}
+	for _, logFile := range append(logFiles, compressed...) {
+		if err := addFileToBundle(bundle, logFile, log); err != nil {
+			log.Warning("failed to add %s to the diagnostic bundle: %v", logFile, err)
+		}
+	}
+
+	if err := bundle.Close(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func addToBundle(bundle *zip.Writer, name string, content io.Reader) error {
+	w, err := bundle.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// addFileToBundle adds a log file with secrets redacted, since files written
+// before a secret was known may still contain it. Rotated files are
+// decompressed first so they can be redacted as well.
+func addFileToBundle(bundle *zip.Writer, path string, log *logger.Logger) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var content io.Reader = file
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+
+		content = zr
+		name = strings.TrimSuffix(name, ".gz")
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	return addToBundle(bundle, "logs/"+name, strings.NewReader(log.Redact(string(data))))
+}
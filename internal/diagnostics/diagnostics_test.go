@@ -0,0 +1,91 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testSecret    = "k3y-0f-the-t3st-account"
+	testSignature = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"
+	testBalance   = "1234.5678"
+)
+
+// sensitive is written to the log files before the secret is registered, as
+// happens when a key is configured while the application runs.
+const sensitive = "key " + testSecret + " sign=" + testSignature + ` {"available":"` + testBalance + `"}` + "\n"
+
+func TestWriteBundleRedactsLogs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	log := logger.GetInstance()
+	defer log.Close()
+
+	current, err := os.OpenFile(log.GetLogFilePath(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.WriteString(sensitive)
+	current.Close()
+
+	rolled, err := os.Create(filepath.Join(app.GetLogDirectory(), "daily-pnl-2026-01-02.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(rolled)
+	zw.Write([]byte(sensitive))
+	zw.Close()
+	rolled.Close()
+
+	log.AddSecrets(testSecret)
+	log.Error("request with key %s failed", testSecret)
+	log.Error(`unexpected response: {"available":"%s"}`, testBalance)
+
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	if err := WriteBundle(path, &config.Config{ApiKey: testSecret}, log); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bundle.Close()
+
+	names := make(map[string]bool)
+	for _, file := range bundle.File {
+		names[file.Name] = true
+
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, leak := range []string{testSecret, testSignature, testBalance} {
+			if strings.Contains(string(data), leak) {
+				t.Errorf("%s contains %q:\n%s", file.Name, leak, data)
+			}
+		}
+	}
+
+	for _, name := range []string{"entries.log", "config.txt", "logs/" + filepath.Base(log.GetLogFilePath()), "logs/daily-pnl-2026-01-02.log"} {
+		if !names[name] {
+			t.Errorf("bundle does not contain %s", name)
+		}
+	}
+}
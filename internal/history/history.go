@@ -0,0 +1,234 @@
+package history
+
+import (
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/tradingday"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const DateFormat = tradingday.DateFormat
+
+// Day is the record of a single trading day.
+type Day struct {
+	Date string `json:"date"`
+	// StartingBalance is the account equity at the start of the trading day,
+	// zero when it is unknown.
+	StartingBalance float64 `json:"starting_balance,omitempty"`
+	RealizedPnl     float64 `json:"realized_pnl"`
+	// Currency is the reporting currency StartingBalance and RealizedPnl are
+	// expressed in; PerCoin holds the realized P&L in each margin coin.
+	Currency string             `json:"currency,omitempty"`
+	PerCoin  map[string]float64 `json:"per_coin,omitempty"`
+	// Positions are the positions closed during the day.
+	Positions []ClosedPosition `json:"positions,omitempty"`
+	// Timeline holds every change of RealizedPnl during the day.
+	Timeline []Point `json:"timeline,omitempty"`
+}
+
+// Point is the realized P&L at a moment of the trading day.
+type Point struct {
+	Time time.Time `json:"time"`
+	Pnl  float64   `json:"pnl"`
+}
+
+// ClosedPosition is a position closed during a trading day. RealizedPnl is in
+// MarginCoin.
+type ClosedPosition struct {
+	ID          string  `json:"id"`
+	Symbol      string  `json:"symbol"`
+	Side        string  `json:"side"`
+	MarginCoin  string  `json:"margin_coin"`
+	RealizedPnl float64 `json:"realized_pnl"`
+}
+
+// ReturnPercent is the realized P&L in percent of the starting balance. The
+// second value is false when the starting balance is unknown.
+func (d Day) ReturnPercent() (float64, bool) {
+	if d.StartingBalance <= 0 {
+		return 0, false
+	}
+
+	return d.RealizedPnl / d.StartingBalance * 100, true
+}
+
+// Store keeps the daily history in a JSON file. The file is re-read when
+// another process, e.g. a backfill run from the command line, changed it.
+type Store struct {
+	path    string
+	mtx     sync.Mutex
+	days    map[string]*Day
+	modTime time.Time
+}
+
+var (
+	stores    = make(map[string]*Store)
+	storesMtx sync.Mutex
+)
+
+// Open returns the store for path. Within a process every caller shares the
+// same store.
+func Open(path string) (*Store, error) {
+	storesMtx.Lock()
+	defer storesMtx.Unlock()
+
+	if store, ok := stores[path]; ok {
+		return store, nil
+	}
+
+	store := &Store{
+		path: path,
+		days: make(map[string]*Day),
+	}
+	stores[path] = store
+
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+
+	return store, store.load()
+}
+
+// refresh reloads the file when it changed on disk. The caller must hold
+// s.mtx.
+func (s *Store) refresh() {
+	info, err := os.Stat(s.path)
+	if err != nil || info.ModTime().Equal(s.modTime) {
+		return
+	}
+
+	if err := s.load(); err != nil {
+		logger.Component(logger.ComponentPnl).Warning("could not reload history: %v", err)
+	}
+}
+
+func (s *Store) load() error {
+	path := s.path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read history: %w", err)
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		s.modTime = info.ModTime()
+	}
+
+	var days []*Day
+	if err := json.Unmarshal(data, &days); err != nil {
+		// Keep the unreadable file for inspection instead of overwriting it
+		// with the next update.
+		corruptPath := path + ".corrupt"
+		if renameErr := os.Rename(path, corruptPath); renameErr != nil {
+			return fmt.Errorf("could not parse history: %w (moving it aside failed: %v)", err, renameErr)
+		}
+		return fmt.Errorf("could not parse history, moved it to %s: %w", corruptPath, err)
+	}
+
+	s.days = make(map[string]*Day, len(days))
+	for _, day := range days {
+		s.days[day.Date] = day
+	}
+
+	return nil
+}
+
+// Day returns the record for date, formatted as DateFormat.
+func (s *Store) Day(date string) (Day, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.refresh()
+
+	day, ok := s.days[date]
+	if !ok {
+		return Day{Date: date}, false
+	}
+
+	return *day, true
+}
+
+// Days returns all records in chronological order.
+func (s *Store) Days() []Day {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.refresh()
+
+	days := make([]Day, 0, len(s.days))
+	for _, day := range s.days {
+		days = append(days, *day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	return days
+}
+
+// Update changes the record for date and writes the history to disk.
+func (s *Store) Update(date string, update func(day *Day)) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.refresh()
+
+	day, ok := s.days[date]
+	if !ok {
+		day = &Day{Date: date}
+		s.days[date] = day
+	}
+
+	update(day)
+
+	return s.save()
+}
+
+// Flush writes the history to disk.
+func (s *Store) Flush() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.save()
+}
+
+func (s *Store) save() error {
+	days := make([]*Day, 0, len(s.days))
+	for _, day := range s.days {
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	data, err := json.MarshalIndent(days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("could not create history directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("could not write history: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("could not write history: %w", err)
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Components that can be given their own level.
+const (
+	ComponentPnl    = "pnl"
+	ComponentConfig = "config"
+	ComponentWs     = "ws"
+	ComponentUI     = "ui"
+)
+
+// ComponentKey is the field Component stores the component name in.
+const ComponentKey = "component"
+
+var Components = []string{ComponentPnl, ComponentConfig, ComponentWs, ComponentUI}
+
+var Levels = []LogLevel{Debug, Info, Warning, Error}
+
+// ParseLevel parses a level name as written in the configuration.
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return Debug, nil
+	case "info":
+		return Info, nil
+	case "warn", "warning":
+		return Warning, nil
+	case "error":
+		return Error, nil
+	default:
+		return Info, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// Component returns the application logger for the named component.
+func Component(name string) *Logger {
+	return GetInstance().Component(name)
+}
+
+// Component returns a logger whose entries belong to the named component and
+// are filtered by its level.
+func (l *Logger) Component(name string) *Logger {
+	logger := l.With(Fields{ComponentKey: name})
+	logger.component = name
+
+	return logger
+}
+
+// Enabled reports whether entries of level are logged.
+func (l *Logger) Enabled(level LogLevel) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	minimum, ok := l.levels[l.component]
+	if !ok {
+		minimum = l.level
+	}
+
+	return level >= minimum
+}
+
+// Level returns the level of components without a level of their own.
+func (l *Logger) Level() LogLevel {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.level
+}
+
+// SetLevel changes the level of components without a level of their own.
+func (l *Logger) SetLevel(level LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.level = level
+}
+
+// SetLevels replaces the default level and all component levels.
+func (l *Logger) SetLevels(level LogLevel, components map[string]LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.level = level
+	l.levels = make(map[string]LogLevel, len(components))
+	for component, level := range components {
+		l.levels[component] = level
+	}
+}
@@ -3,8 +3,10 @@ package logger
 import (
 	"daily-profit-and-loss/internal/app"
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,10 +22,27 @@ const (
 	Error
 )
 
+// Fields are key-value pairs attached to log entries.
+type Fields map[string]interface{}
+
+// ErrorKey is the field WithError stores the error in.
+const ErrorKey = "error"
+
 type LogEntry struct {
 	Timestamp time.Time
 	Level     LogLevel
 	Message   string
+	Fields    Fields
+}
+
+func (e LogEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s", e.Timestamp.Format("2006-01-02 15:04:05"), e.Level, e.Message)
+	for _, key := range slices.Sorted(maps.Keys(e.Fields)) {
+		fmt.Fprintf(&b, " %s=%v", key, e.Fields[key])
+	}
+
+	return b.String()
 }
 
 func (l LogLevel) String() string {
@@ -41,79 +60,221 @@ func (l LogLevel) String() string {
 	}
 }
 
+// DefaultCapacity is the number of entries kept in memory unless SetCapacity
+// changes it.
+const DefaultCapacity = 5000
+
+// Output formats of the log files.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const timestampFormat = "2006-01-02 15:04:05"
+
 var (
 	instance *Logger
 	once     sync.Once
 )
 
+// Logger writes to the log files and keeps recent entries in memory. Loggers
+// returned by With share both with the logger they were derived from.
 type Logger struct {
-	entries     []LogEntry
+	*core
+	fields    Fields
+	component string
+}
+
+type core struct {
+	entries     *ring
+	subscribers map[int]chan LogEntry
+	nextID      int
 	mu          sync.Mutex
 	logrus      *logrus.Logger
-	logFile     *os.File
-	logFilePath string
+	output      *rotatingFile
+	level       LogLevel
+	levels      map[string]LogLevel
+	secrets     map[string]bool
 }
 
 func GetInstance() *Logger {
 	once.Do(func() {
+		instance = newLogger(app.GetLogDirectory())
+		instance.Debug("logging to %s", instance.GetLogFilePath())
+	})
+	return instance
+}
 
-		logrusLogger := logrus.New()
-
-		logrusLogger.SetFormatter(&logrus.TextFormatter{
-			FullTimestamp:   true,
-			TimestampFormat: "2006-01-02 15:04:05",
-		})
+// newLogger creates a logger writing its files to logDir.
+func newLogger(logDir string) *Logger {
+	logrusLogger := logrus.New()
 
-		logDir := app.GetLogDirectory()
-		if err := os.MkdirAll(logDir, 0755); err != nil {
-			fmt.Printf("Failed to create log directory: %v\n", err)
-		}
+	logrusLogger.SetFormatter(formatter(FormatText))
+	logrusLogger.SetLevel(logrus.DebugLevel)
 
-		logFilePath := filepath.Join(logDir, fmt.Sprintf("daily-pnl-%s.log", time.Now().Format("2006-01-02")))
-		logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		if err != nil {
-			fmt.Printf("Failed to open log file: %v\n", err)
-		} else {
-			logrusLogger.SetOutput(logFile)
-		}
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		fmt.Printf("Failed to create log directory: %v\n", err)
+	}
 
-		instance = &Logger{
-			entries:     make([]LogEntry, 0),
-			logrus:      logrusLogger,
-			logFile:     logFile,
-			logFilePath: logFilePath,
-		}
+	output, err := newRotatingFile(logDir)
+	if err != nil {
+		fmt.Printf("Failed to open log file: %v\n", err)
+	} else {
+		logrusLogger.SetOutput(output)
+	}
 
-		instance.Debug("logging to %s", instance.GetLogFilePath())
-	})
-	return instance
+	return &Logger{core: &core{
+		entries:     newRing(DefaultCapacity),
+		subscribers: make(map[int]chan LogEntry),
+		logrus:      logrusLogger,
+		output:      output,
+		level:       Info,
+		levels:      make(map[string]LogLevel),
+		secrets:     make(map[string]bool),
+	}}
 }
 
 func (l *Logger) GetLogFilePath() string {
-	return l.logFilePath
+	if l.output == nil {
+		return ""
+	}
+
+	return l.output.currentPath()
+}
+
+// SetRotation changes when log files are rotated and how long they are kept.
+func (l *Logger) SetRotation(rotation Rotation) {
+	if l.output == nil {
+		return
+	}
+
+	if err := l.output.setRotation(rotation); err != nil {
+		fmt.Printf("Failed to open log file: %v\n", err)
+	}
+}
+
+// With returns a logger that adds fields to every entry it logs.
+func (l *Logger) With(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	maps.Copy(merged, l.fields)
+	maps.Copy(merged, fields)
+
+	return &Logger{core: l.core, fields: merged, component: l.component}
+}
+
+// WithError returns a logger that adds err to every entry it logs.
+func (l *Logger) WithError(err error) *Logger {
+	return l.With(Fields{ErrorKey: err})
+}
+
+// SetFormat switches the log files between FormatText and FormatJSON.
+func (l *Logger) SetFormat(format string) {
+	l.logrus.SetFormatter(formatter(format))
+}
+
+func formatter(format string) logrus.Formatter {
+	if format == FormatJSON {
+		return &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano}
+	}
+
+	return &logrus.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: timestampFormat,
+	}
+}
+
+// Entries returns a copy of the entries kept in memory, oldest first.
+func (l *Logger) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.entries.slice()
+}
+
+// Capacity returns the number of entries kept in memory.
+func (l *Logger) Capacity() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.entries.entries)
+}
+
+// SetCapacity changes the number of entries kept in memory. The oldest
+// entries are dropped when it shrinks.
+func (l *Logger) SetCapacity(capacity int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if capacity < 1 || capacity == len(l.entries.entries) {
+		return
+	}
+
+	l.entries.resize(capacity)
+}
+
+// Subscribe delivers every new entry to the returned channel until cancel is
+// called. Entries are dropped for a subscriber whose buffer is full, so a slow
+// reader never blocks logging.
+func (l *Logger) Subscribe(buffer int) (entries <-chan LogEntry, cancel func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.subscribe(buffer)
+}
+
+// Follow is Subscribe that also returns the entries kept in memory, so no
+// entry is missed or seen twice between reading them and subscribing.
+func (l *Logger) Follow(buffer int) (current []LogEntry, entries <-chan LogEntry, cancel func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, cancel = l.subscribe(buffer)
+	return l.entries.slice(), entries, cancel
+}
+
+// subscribe registers a subscriber. The caller must hold l.mu.
+func (l *Logger) subscribe(buffer int) (<-chan LogEntry, func()) {
+	id := l.nextID
+	l.nextID++
+
+	ch := make(chan LogEntry, buffer)
+	l.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			delete(l.subscribers, id)
+			close(ch)
+		})
+	}
 }
 
 func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
 	entry := LogEntry{
 		Timestamp: time.Now(),
 		Level:     level,
-		Message:   fmt.Sprintf(format, args...),
+		Message:   l.Redact(fmt.Sprintf(format, args...)),
+		Fields:    l.redactFields(l.fields),
 	}
 
 	l.mu.Lock()
-	l.entries = append(l.entries, entry)
+	l.entries.push(entry)
+	for _, subscriber := range l.subscribers {
+		select {
+		case subscriber <- entry:
+		default:
+		}
+	}
 	l.mu.Unlock()
 
-	switch level {
-	case Debug:
-		l.logrus.Debugf(format, args...)
-	case Info:
-		l.logrus.Infof(format, args...)
-	case Warning:
-		l.logrus.Warnf(format, args...)
-	case Error:
-		l.logrus.Errorf(format, args...)
-	}
+	l.logrus.WithFields(logrus.Fields(entry.Fields)).Log(logrusLevel(level), entry.Message)
 }
 
 func (l *Logger) Debug(format string, args ...interface{}) {
@@ -132,23 +293,23 @@ func (l *Logger) Error(format string, args ...interface{}) {
 	l.log(Error, format, args...)
 }
 
-func (l *Logger) SetLevel(level LogLevel) {
+func logrusLevel(level LogLevel) logrus.Level {
 	switch level {
 	case Debug:
-		l.logrus.SetLevel(logrus.DebugLevel)
+		return logrus.DebugLevel
 	case Info:
-		l.logrus.SetLevel(logrus.InfoLevel)
+		return logrus.InfoLevel
 	case Warning:
-		l.logrus.SetLevel(logrus.WarnLevel)
+		return logrus.WarnLevel
 	case Error:
-		l.logrus.SetLevel(logrus.ErrorLevel)
+		return logrus.ErrorLevel
 	default:
-		l.logrus.SetLevel(logrus.InfoLevel)
+		return logrus.InfoLevel
 	}
 }
 
 func (l *Logger) Close() {
-	if l.logFile != nil {
-		l.logFile.Close()
+	if l.output != nil {
+		l.output.Close()
 	}
-}
\ No newline at end of file
+}
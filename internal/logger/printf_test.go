@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestVetChecksPrintfWrappers makes sure go vet still recognizes the logging
+// methods as printf wrappers, so the CI vet job catches misuse such as
+// log.Error("x:", err).
+func TestVetChecksPrintfWrappers(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available")
+	}
+
+	output, err := exec.Command(goTool, "vet", "./testdata/printf").CombinedOutput()
+	if err == nil {
+		t.Fatalf("go vet accepted the misuse in testdata/printf:\n%s", output)
+	}
+
+	for _, want := range []string{
+		"Logger).Error call has arguments but no formatting directives",
+		"Logger).Warning format %d has arg \"three\" of wrong type string",
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("go vet output does not report %q:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secrets in log entries.
+const Redacted = "[REDACTED]"
+
+// minSecretLength keeps short values, which would match ordinary text, from
+// being registered as secrets.
+const minSecretLength = 8
+
+var (
+	// credentialValue matches key=value, key: value and "key":"value" pairs
+	// whose key names a credential or a request signature.
+	credentialValue = regexp.MustCompile(`(?i)("?\b(?:api[_-]?key|secret(?:[_-]?key)?|passphrase|password|token|sign(?:ature)?|nonce)"?\s*[:=]\s*"?)[^"\s,&}]+`)
+	// balanceValue matches account figures in JSON responses quoted by error
+	// messages of the exchange client.
+	balanceValue = regexp.MustCompile(`(?i)("(?:available|balance|equity|frozen|margin|crossUnrealizedPNL|isolationUnrealizedPNL|bonus)"\s*:\s*"?)[^"\s,}]+`)
+	// bearerToken matches authorization headers.
+	bearerToken = regexp.MustCompile(`(?i)(bearer\s+)\S+`)
+	// longHex matches hex strings of the length of API keys and SHA-256
+	// signatures.
+	longHex = regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`)
+)
+
+// AddSecrets registers values that are replaced wherever they appear in log
+// entries, such as the configured API keys. Values stay registered for the
+// lifetime of the process so an old key is still scrubbed after it changed.
+func (l *Logger) AddSecrets(values ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < minSecretLength || l.secrets[value] {
+			continue
+		}
+		l.secrets[value] = true
+	}
+}
+
+// Redact removes registered secrets and anything that looks like a key, a
+// signature or a balance from s.
+func (l *Logger) Redact(s string) string {
+	l.mu.Lock()
+	for secret := range l.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	l.mu.Unlock()
+
+	s = credentialValue.ReplaceAllString(s, "${1}"+Redacted)
+	s = balanceValue.ReplaceAllString(s, "${1}"+Redacted)
+	s = bearerToken.ReplaceAllString(s, "${1}"+Redacted)
+	return longHex.ReplaceAllString(s, Redacted)
+}
+
+// redactFields returns fields with text and error values redacted.
+func (l *Logger) redactFields(fields Fields) Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make(Fields, len(fields))
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			redacted[key] = l.Redact(v)
+		case error:
+			redacted[key] = l.Redact(v.Error())
+		default:
+			redacted[key] = value
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testSecret    = "k3y-0f-the-t3st-account"
+	testNonce     = "n0nc3v4lu3"
+	testSignature = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0"
+)
+
+// leaks are the values that must never reach the log.
+var leaks = []string{testSecret, testNonce, testSignature, "1234.5678", "12.34", "56.78"}
+
+// logSensitive logs a registered secret, a signed request and a balance
+// response of the exchange.
+func logSensitive(l *Logger) {
+	l.AddSecrets(testSecret)
+
+	l.Info("connecting with key %s", testSecret)
+	l.Info("GET /api/v1/futures/account?marginCoin=USDT&nonce=%s&timestamp=1700000000000&sign=%s", testNonce, testSignature)
+	l.Warning(`unexpected response: {"code":0,"data":{"marginCoin":"USDT","available":"1234.5678","frozen":"12.34","margin":56.78}}`)
+	l.WithError(io.ErrUnexpectedEOF).With(Fields{"key": testSecret}).Error("request failed")
+}
+
+func assertRedacted(t *testing.T, where, text string) {
+	t.Helper()
+
+	for _, leak := range leaks {
+		if strings.Contains(text, leak) {
+			t.Errorf("%s contains %q:\n%s", where, leak, text)
+		}
+	}
+	if !strings.Contains(text, Redacted) {
+		t.Errorf("%s does not contain %s:\n%s", where, Redacted, text)
+	}
+}
+
+func TestRedactRingBuffer(t *testing.T) {
+	l := newLogger(t.TempDir())
+	defer l.Close()
+
+	logSensitive(l)
+
+	var entries strings.Builder
+	for _, entry := range l.Entries() {
+		entries.WriteString(entry.String() + "\n")
+	}
+	assertRedacted(t, "ring buffer", entries.String())
+}
+
+func TestRedactRotatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	l := newLogger(dir)
+
+	rotation := defaultRotation()
+	rotation.MaxSize = 256
+	l.SetRotation(rotation)
+	l.SetFormat(FormatJSON)
+
+	for i := 0; i < 3; i++ {
+		logSensitive(l)
+	}
+	l.Close()
+
+	// Compress the rolled over files now so none is compressed while it
+	// is read below.
+	l.output.cleanup()
+
+	files, err := filepath.Glob(filepath.Join(dir, logPrefix+"*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := 0
+	for _, path := range files {
+		if strings.HasSuffix(path, ".gz") {
+			compressed++
+		}
+		assertRedacted(t, filepath.Base(path), readLogFile(t, path))
+	}
+	if compressed == 0 {
+		t.Errorf("no rotated file among %v", files)
+	}
+}
+
+func readLogFile(t *testing.T, path string) string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var content io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		content = zr
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,46 @@
+package logger
+
+// ring keeps the most recent entries up to its capacity.
+type ring struct {
+	entries []LogEntry
+	start   int
+	size    int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{entries: make([]LogEntry, max(capacity, 1))}
+}
+
+func (r *ring) push(entry LogEntry) {
+	end := (r.start + r.size) % len(r.entries)
+	r.entries[end] = entry
+
+	if r.size < len(r.entries) {
+		r.size++
+	} else {
+		r.start = (r.start + 1) % len(r.entries)
+	}
+}
+
+// slice returns the entries from oldest to newest.
+func (r *ring) slice() []LogEntry {
+	entries := make([]LogEntry, r.size)
+	for i := range entries {
+		entries[i] = r.entries[(r.start+i)%len(r.entries)]
+	}
+
+	return entries
+}
+
+// resize changes the capacity, keeping the newest entries.
+func (r *ring) resize(capacity int) {
+	entries := r.slice()
+	*r = *newRing(capacity)
+
+	if len(entries) > len(r.entries) {
+		entries = entries[len(entries)-len(r.entries):]
+	}
+	for _, entry := range entries {
+		r.push(entry)
+	}
+}
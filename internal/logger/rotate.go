@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logPrefix = "daily-pnl-"
+	logSuffix = ".log"
+
+	// DefaultMaxSize is the size in bytes at which a log file is rotated
+	// within the day.
+	DefaultMaxSize = 10 << 20
+	// DefaultRetention is how long old log files are kept.
+	DefaultRetention = 30 * 24 * time.Hour
+)
+
+// Rotation configures when log files are rotated and how long they are kept.
+type Rotation struct {
+	// Date names the day a moment belongs to, e.g. the trading day. A new
+	// file is started when it changes.
+	Date func(time.Time) string
+	// MaxSize rotates the file once it would grow beyond this many bytes;
+	// zero disables rotation by size.
+	MaxSize int64
+	// Retention deletes rotated files older than this; zero keeps them.
+	Retention time.Duration
+}
+
+func defaultRotation() Rotation {
+	return Rotation{
+		Date:      func(t time.Time) string { return t.Format("2006-01-02") },
+		MaxSize:   DefaultMaxSize,
+		Retention: DefaultRetention,
+	}
+}
+
+// rotatingFile writes to daily-pnl-<date>.log in dir and starts a new file
+// when the date changes or the file gets too large. Finished files are
+// compressed and expired ones deleted in the background.
+type rotatingFile struct {
+	mu       sync.Mutex
+	cleaning sync.Mutex
+	dir      string
+	rotation Rotation
+	file     *os.File
+	path     string
+	date     string
+	size     int64
+}
+
+func newRotatingFile(dir string) (*rotatingFile, error) {
+	r := &rotatingFile{dir: dir, rotation: defaultRotation()}
+
+	if err := r.open(time.Now()); err != nil {
+		return nil, err
+	}
+	go r.cleanup()
+
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	switch {
+	case r.file == nil || r.rotation.Date(now) != r.date:
+		if err := r.open(now); err != nil {
+			return 0, err
+		}
+		go r.cleanup()
+	case r.rotation.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.rotation.MaxSize:
+		if err := r.rollOver(now); err != nil {
+			return 0, err
+		}
+		go r.cleanup()
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// open closes the current file and opens the file of the date of now. The
+// caller must hold r.mu.
+func (r *rotatingFile) open(now time.Time) error {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+
+	r.date = r.rotation.Date(now)
+	r.path = filepath.Join(r.dir, logPrefix+r.date+logSuffix)
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	r.size = 0
+	if info, err := file.Stat(); err == nil {
+		r.size = info.Size()
+	}
+	r.file = file
+
+	return nil
+}
+
+// rollOver moves the full file aside and continues in a new one. The caller
+// must hold r.mu.
+func (r *rotatingFile) rollOver(now time.Time) error {
+	r.file.Close()
+	r.file = nil
+
+	if err := os.Rename(r.path, r.rolledPath(now)); err != nil {
+		return err
+	}
+
+	return r.open(now)
+}
+
+// rolledPath names a rolled over file by the time of day, adding a counter
+// when a file of the same second already exists, compressed or not.
+func (r *rotatingFile) rolledPath(now time.Time) string {
+	stamp := now.Format("150405")
+	for i := 1; ; i++ {
+		rolled := filepath.Join(r.dir, fmt.Sprintf("%s%s.%s%s", logPrefix, r.date, stamp, logSuffix))
+		if !exists(rolled) && !exists(rolled+".gz") {
+			return rolled
+		}
+		stamp = fmt.Sprintf("%s-%d", now.Format("150405"), i)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// setRotation applies rotation and switches files if the date changed.
+func (r *rotatingFile) setRotation(rotation Rotation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rotation = rotation
+	if rotation.Date(time.Now()) == r.date {
+		return nil
+	}
+
+	err := r.open(time.Now())
+	go r.cleanup()
+	return err
+}
+
+func (r *rotatingFile) currentPath() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.path
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// cleanup compresses finished log files and deletes expired ones.
+func (r *rotatingFile) cleanup() {
+	r.cleaning.Lock()
+	defer r.cleaning.Unlock()
+
+	r.mu.Lock()
+	current, retention := r.path, r.rotation.Retention
+	r.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(r.dir, logPrefix+"*"))
+	if err != nil {
+		return
+	}
+
+	for _, path := range files {
+		if path == current {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if retention > 0 && time.Since(info.ModTime()) > retention {
+			if err := os.Remove(path); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to delete old log file %s: %v\n", path, err)
+			}
+			continue
+		}
+
+		if strings.HasSuffix(path, logSuffix) {
+			if err := compress(path); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to compress log file %s: %v\n", path, err)
+			}
+		}
+	}
+}
+
+// compress replaces path with path.gz, keeping its modification time so
+// retention counts from the last write.
+func compress(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+
+	in.Close()
+	if err := os.Chtimes(out.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
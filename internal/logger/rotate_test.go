@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRollOverWithinTheSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	r, err := newRotatingFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := r.Write([]byte("entry\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		r.mu.Lock()
+		err := r.rollOver(now)
+		r.mu.Unlock()
+		if err != nil {
+			t.Fatalf("roll over %d: %v", i, err)
+		}
+	}
+
+	r.cleaning.Lock()
+	defer r.cleaning.Unlock()
+
+	rolled, err := filepath.Glob(filepath.Join(dir, logPrefix+r.date+".*.log*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolled) != 3 {
+		t.Fatalf("got rolled files %v, want 3", rolled)
+	}
+	for _, path := range rolled {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("rolled file %s is missing or empty: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Package printf misuses the logger so that go vet has something to report.
+package printf
+
+import (
+	"daily-profit-and-loss/internal/logger"
+	"errors"
+)
+
+func misuse() {
+	log := logger.GetInstance()
+	err := errors.New("failure")
+
+	log.Error("x:", err)
+	log.Component(logger.ComponentPnl).Warning("%d trades", "three")
+}
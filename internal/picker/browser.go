@@ -0,0 +1,166 @@
+package picker
+
+import (
+	"daily-profit-and-loss/internal/ui"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// Browser is a directory browser drawn with Gio, used on platforms without a
+// native dialog.
+type Browser struct{}
+
+type browserEntry struct {
+	name   string
+	isDir  bool
+	button widget.Clickable
+}
+
+func (b *Browser) Pick(opts Options) (string, error) {
+	w := new(app.Window)
+	w.Option(app.Title(opts.title()))
+	w.Option(app.Size(unit.Dp(450), unit.Dp(550)))
+
+	selected := make(chan string, 1)
+	if err := runBrowser(w, opts, selected); err != nil {
+		return "", err
+	}
+
+	select {
+	case path := <-selected:
+		return path, nil
+	default:
+		return "", ErrCanceled
+	}
+}
+
+func runBrowser(w *app.Window, opts Options, selected chan<- string) error {
+	th := material.NewTheme()
+
+	var (
+		upButton     widget.Clickable
+		selectButton widget.Clickable
+		cancelButton widget.Clickable
+		nameInput    widget.Editor
+		list         = widget.List{List: layout.List{Axis: layout.Vertical}}
+		entries      []*browserEntry
+		status       string
+	)
+
+	nameInput.SingleLine = true
+	nameInput.SetText(opts.DefaultName)
+
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		dir = "."
+	}
+
+	load := func(path string) {
+		items, err := os.ReadDir(path)
+		if err != nil {
+			status = err.Error()
+			return
+		}
+
+		dir, status, entries = path, "", nil
+		for _, item := range items {
+			if !item.IsDir() && (opts.Mode == ModeFolder || !opts.matches(item.Name())) {
+				continue
+			}
+			entries = append(entries, &browserEntry{name: item.Name(), isDir: item.IsDir()})
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].isDir != entries[j].isDir {
+				return entries[i].isDir
+			}
+			return entries[i].name < entries[j].name
+		})
+	}
+	load(dir)
+
+	handler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
+		th := theme.(*material.Theme)
+
+		ui.CloseButtonHandler(&cancelButton, gtx, closeRequested)
+
+		if upButton.Clicked(gtx) {
+			load(filepath.Dir(dir))
+		}
+
+		for _, entry := range entries {
+			if !entry.button.Clicked(gtx) {
+				continue
+			}
+			if entry.isDir {
+				load(filepath.Join(dir, entry.name))
+				break
+			}
+			nameInput.SetText(entry.name)
+		}
+
+		if selectButton.Clicked(gtx) {
+			path, ok := dir, true
+			if opts.Mode == ModeFile {
+				name := strings.TrimSpace(nameInput.Text())
+				if name == "" {
+					status, ok = "Enter a file name", false
+				} else {
+					path = opts.withExtension(filepath.Join(dir, name))
+				}
+			}
+
+			if ok {
+				select {
+				case selected <- path:
+				default:
+				}
+				select {
+				case closeRequested <- true:
+				default:
+				}
+			}
+		}
+
+		entryList := func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Max.Y = gtx.Dp(unit.Dp(300))
+			gtx.Constraints.Min.Y = gtx.Constraints.Max.Y
+			return material.List(th, &list).Layout(gtx, len(entries), func(gtx layout.Context, i int) layout.Dimensions {
+				entry := entries[i]
+				label := entry.name
+				if entry.isDir {
+					label += string(filepath.Separator)
+				}
+				return material.Clickable(gtx, &entry.button, func(gtx layout.Context) layout.Dimensions {
+					return ui.CommonInsets.Label.Layout(gtx, material.Body1(th, label).Layout)
+				})
+			})
+		}
+
+		widgets := []layout.Widget{
+			ui.InfoText(th, dir),
+			ui.CenteredButton(th, &upButton, "Up"),
+			entryList,
+		}
+		if opts.Mode == ModeFile {
+			widgets = append(widgets, ui.NewLabeledInput(th, "File name:", "pnl.txt", &nameInput).Layout)
+		}
+		widgets = append(widgets,
+			ui.CenteredButton(th, &selectButton, "Select"),
+			ui.CenteredButton(th, &cancelButton, "Cancel"),
+			ui.StatusText(th, status),
+		)
+
+		return ui.VerticalLayout(gtx, widgets...)
+	}
+
+	return ui.RunWindow(w, handler, th)
+}
@@ -0,0 +1,102 @@
+package picker
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrCanceled    = errors.New("selection canceled")
+	ErrUnsupported = errors.New("no native picker available")
+)
+
+type Mode int
+
+const (
+	ModeFolder Mode = iota
+	ModeFile
+)
+
+// Filter restricts the files offered in ModeFile, e.g.
+// Filter{Name: "Text files", Extensions: []string{"txt"}}.
+type Filter struct {
+	Name       string
+	Extensions []string
+}
+
+type Options struct {
+	Title string
+	Mode  Mode
+	// Filters only apply to ModeFile. The first filter's first extension is
+	// appended to file names entered without one.
+	Filters []Filter
+	// DefaultName is suggested as file name in ModeFile.
+	DefaultName string
+}
+
+type Picker interface {
+	Pick(opts Options) (string, error)
+}
+
+// New returns the native picker of the current platform, falling back to the
+// built-in directory browser where no native dialog is available.
+func New() Picker {
+	return chain{native(), &Browser{}}
+}
+
+type chain []Picker
+
+func (c chain) Pick(opts Options) (string, error) {
+	err := ErrUnsupported
+	for _, p := range c {
+		var path string
+		path, err = p.Pick(opts)
+		if !errors.Is(err, ErrUnsupported) {
+			return path, err
+		}
+	}
+
+	return "", err
+}
+
+func (o Options) matches(name string) bool {
+	if len(o.Filters) == 0 {
+		return true
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	for _, filter := range o.Filters {
+		for _, allowed := range filter.Extensions {
+			if allowed == "*" || strings.ToLower(allowed) == ext {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (o Options) withExtension(path string) string {
+	if o.Mode != ModeFile || filepath.Ext(path) != "" || len(o.Filters) == 0 || len(o.Filters[0].Extensions) == 0 {
+		return path
+	}
+
+	ext := o.Filters[0].Extensions[0]
+	if ext == "*" {
+		return path
+	}
+
+	return path + "." + ext
+}
+
+func (o Options) title() string {
+	if o.Title != "" {
+		return o.Title
+	}
+	if o.Mode == ModeFolder {
+		return "Select a folder"
+	}
+
+	return "Select a file"
+}
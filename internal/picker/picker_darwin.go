@@ -0,0 +1,54 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type darwinPicker struct{}
+
+func native() Picker {
+	return darwinPicker{}
+}
+
+func (darwinPicker) Pick(opts Options) (string, error) {
+	var script string
+
+	switch opts.Mode {
+	case ModeFolder:
+		script = fmt.Sprintf(`POSIX path of (choose folder with prompt %s)`, quote(opts.title()))
+	default:
+		script = fmt.Sprintf(`POSIX path of (choose file name with prompt %s default name %s)`, quote(opts.title()), quote(opts.DefaultName))
+	}
+
+	output, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// osascript reports "User canceled" (-128) with exit code 1.
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", ErrCanceled
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+		}
+		return "", fmt.Errorf("error showing picker: %w", err)
+	}
+
+	path := strings.TrimSpace(string(output))
+	if path == "" {
+		return "", ErrCanceled
+	}
+	if opts.Mode == ModeFolder && len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	return opts.withExtension(path), nil
+}
+
+// quote returns s as an AppleScript string literal.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
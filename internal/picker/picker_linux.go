@@ -0,0 +1,214 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	portalDestination = "org.freedesktop.portal.Desktop"
+	portalPath        = "/org/freedesktop/portal/desktop"
+	// portalTimeout ends the wait for a portal that never answers. It is
+	// long enough for the user to browse in the dialog.
+	portalTimeout = 15 * time.Minute
+)
+
+var errPortalTimeout = errors.New("file chooser portal did not respond")
+
+type linuxPicker struct{}
+
+func native() Picker {
+	return linuxPicker{}
+}
+
+// Pick tries the xdg-desktop-portal first, which works in sandboxes and on
+// every desktop that ships a portal, then zenity and kdialog.
+func (linuxPicker) Pick(opts Options) (string, error) {
+	for _, pick := range []func(Options) (string, error){pickPortal, pickZenity, pickKDialog} {
+		path, err := pick(opts)
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return opts.withExtension(path), nil
+	}
+
+	return "", ErrUnsupported
+}
+
+type portalFilter struct {
+	Name     string
+	Patterns []portalPattern
+}
+
+type portalPattern struct {
+	Kind    uint32
+	Pattern string
+}
+
+func pickPortal(opts Options) (string, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	token := fmt.Sprintf("dailypnl%d", time.Now().UnixNano())
+	options := map[string]dbus.Variant{
+		"handle_token": dbus.MakeVariant(token),
+		"modal":        dbus.MakeVariant(true),
+	}
+
+	method := "org.freedesktop.portal.FileChooser.OpenFile"
+	if opts.Mode == ModeFolder {
+		options["directory"] = dbus.MakeVariant(true)
+	} else {
+		method = "org.freedesktop.portal.FileChooser.SaveFile"
+		if opts.DefaultName != "" {
+			options["current_name"] = dbus.MakeVariant(opts.DefaultName)
+		}
+
+		var filters []portalFilter
+		for _, filter := range opts.Filters {
+			f := portalFilter{Name: filter.Name}
+			for _, ext := range filter.Extensions {
+				f.Patterns = append(f.Patterns, portalPattern{Kind: 0, Pattern: "*." + ext})
+			}
+			filters = append(filters, f)
+		}
+		if len(filters) > 0 {
+			options["filters"] = dbus.MakeVariant(filters)
+		}
+	}
+
+	// Subscribe before calling so a fast response is not missed.
+	sender := strings.ReplaceAll(strings.TrimPrefix(conn.Names()[0], ":"), ".", "_")
+	requestPath := dbus.ObjectPath(fmt.Sprintf("%s/request/%s/%s", portalPath, sender, token))
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(requestPath),
+		dbus.WithMatchInterface("org.freedesktop.portal.Request"),
+		dbus.WithMatchMember("Response"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	defer conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 1)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	var handle dbus.ObjectPath
+	obj := conn.Object(portalDestination, portalPath)
+	if err := obj.Call(method, 0, "", opts.title(), options).Store(&handle); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	timeout := time.NewTimer(portalTimeout)
+	defer timeout.Stop()
+
+	for {
+		var signal *dbus.Signal
+		select {
+		case signal = <-signals:
+		case <-timeout.C:
+			conn.Object(portalDestination, handle).Call("org.freedesktop.portal.Request.Close", 0)
+			return "", errPortalTimeout
+		}
+		if signal == nil {
+			return "", ErrCanceled
+		}
+		if signal.Path != handle || len(signal.Body) < 2 {
+			continue
+		}
+
+		if response, ok := signal.Body[0].(uint32); !ok || response != 0 {
+			return "", ErrCanceled
+		}
+
+		results, ok := signal.Body[1].(map[string]dbus.Variant)
+		if !ok {
+			return "", fmt.Errorf("unexpected portal response")
+		}
+
+		uris, ok := results["uris"].Value().([]string)
+		if !ok || len(uris) == 0 {
+			return "", ErrCanceled
+		}
+
+		u, err := url.Parse(uris[0])
+		if err != nil {
+			return "", fmt.Errorf("invalid path returned by portal: %w", err)
+		}
+
+		return u.Path, nil
+	}
+}
+
+func pickZenity(opts Options) (string, error) {
+	args := []string{"--file-selection", "--title=" + opts.title()}
+	if opts.Mode == ModeFolder {
+		args = append(args, "--directory")
+	} else {
+		args = append(args, "--save")
+		if opts.DefaultName != "" {
+			args = append(args, "--filename="+opts.DefaultName)
+		}
+		for _, filter := range opts.Filters {
+			var patterns []string
+			for _, ext := range filter.Extensions {
+				patterns = append(patterns, "*."+ext)
+			}
+			args = append(args, fmt.Sprintf("--file-filter=%s | %s", filter.Name, strings.Join(patterns, " ")))
+		}
+	}
+
+	return run("zenity", args...)
+}
+
+func pickKDialog(opts Options) (string, error) {
+	var args []string
+	if opts.Mode == ModeFolder {
+		args = []string{"--title", opts.title(), "--getexistingdirectory", "."}
+	} else {
+		var patterns []string
+		for _, filter := range opts.Filters {
+			for _, ext := range filter.Extensions {
+				patterns = append(patterns, "*."+ext)
+			}
+		}
+		args = []string{"--title", opts.title(), "--getsavefilename", opts.DefaultName, strings.Join(patterns, " ")}
+	}
+
+	return run("kdialog", args...)
+}
+
+func run(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", ErrCanceled
+		}
+		return "", fmt.Errorf("error running %s: %w", name, err)
+	}
+
+	path := strings.TrimSpace(string(output))
+	if path == "" {
+		return "", ErrCanceled
+	}
+
+	return path, nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows && !darwin && !linux
+
+package picker
+
+func native() Picker {
+	return unsupported{}
+}
+
+type unsupported struct{}
+
+func (unsupported) Pick(Options) (string, error) {
+	return "", ErrUnsupported
+}
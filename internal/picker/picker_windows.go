@@ -0,0 +1,82 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type windowsPicker struct{}
+
+func native() Picker {
+	return windowsPicker{}
+}
+
+func (windowsPicker) Pick(opts Options) (string, error) {
+	var script string
+
+	switch opts.Mode {
+	case ModeFolder:
+		script = fmt.Sprintf(`
+Add-Type -AssemblyName System.Windows.Forms
+$folderBrowser = New-Object System.Windows.Forms.FolderBrowserDialog
+$folderBrowser.Description = %s
+$folderBrowser.RootFolder = [System.Environment+SpecialFolder]::MyComputer
+if ($folderBrowser.ShowDialog() -eq [System.Windows.Forms.DialogResult]::OK) {
+    Write-Output $folderBrowser.SelectedPath
+}
+`, quote(opts.title()))
+	default:
+		script = fmt.Sprintf(`
+Add-Type -AssemblyName System.Windows.Forms
+$fileDialog = New-Object System.Windows.Forms.SaveFileDialog
+$fileDialog.Title = %s
+$fileDialog.Filter = %s
+$fileDialog.FileName = %s
+$fileDialog.OverwritePrompt = $false
+if ($fileDialog.ShowDialog() -eq [System.Windows.Forms.DialogResult]::OK) {
+    Write-Output $fileDialog.FileName
+}
+`, quote(opts.title()), quote(filterString(opts.Filters)), quote(opts.DefaultName))
+	}
+
+	output, err := exec.Command("powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", ErrCanceled
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("%w: %v", ErrUnsupported, err)
+		}
+		return "", fmt.Errorf("error showing picker: %w", err)
+	}
+
+	path := strings.TrimSpace(string(output))
+	if path == "" {
+		return "", ErrCanceled
+	}
+
+	return opts.withExtension(path), nil
+}
+
+// quote returns s as a single-quoted PowerShell string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func filterString(filters []Filter) string {
+	var parts []string
+	for _, filter := range filters {
+		var patterns []string
+		for _, ext := range filter.Extensions {
+			patterns = append(patterns, "*."+ext)
+		}
+		pattern := strings.Join(patterns, ";")
+		parts = append(parts, fmt.Sprintf("%s (%s)|%s", filter.Name, pattern, pattern))
+	}
+	parts = append(parts, "All files (*.*)|*.*")
+
+	return strings.Join(parts, "|")
+}
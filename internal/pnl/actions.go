@@ -0,0 +1,85 @@
+package pnl
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// OpenOutputFolder opens the folder of the P&L output file in the file
+// manager.
+func OpenOutputFolder(cfg *config.Config, log *logger.Logger) {
+	cfg.Mtx.Lock()
+	path := cfg.ProfitAndLossFile
+	cfg.Mtx.Unlock()
+
+	if path == "" {
+		log.Warning("no output file configured")
+		return
+	}
+
+	folder := filepath.Dir(outputPath(path))
+	log.Info("opening output folder: %s", folder)
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer.exe", folder)
+	case "darwin":
+		cmd = exec.Command("open", folder)
+	default:
+		cmd = exec.Command("xdg-open", folder)
+	}
+
+	if err := cmd.Start(); err != nil {
+		log.Error("Failed to open output folder: %v", err)
+	}
+}
+
+// CopyTodayPnl copies today's P&L, as shown in the tray, to the clipboard.
+func CopyTodayPnl(cfg *config.Config, log *logger.Logger) {
+	snapshot := CurrentState().Snapshot
+	if snapshot.Date == "" {
+		log.Warning("no pnl to copy yet")
+		return
+	}
+
+	text := fmt.Sprintf("%s: %s", snapshot.Date, formatPnl(cfg, snapshot.Today, snapshot.StartingBalance))
+	if err := copyToClipboard(text); err != nil {
+		log.Error("Failed to copy pnl to the clipboard: %v", err)
+		return
+	}
+
+	log.Info("copied pnl to the clipboard")
+}
+
+// copyToClipboard writes text to the system clipboard with the platform's
+// command line tools.
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	default:
+		candidates = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(candidate[0], candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+
+	return errors.New("no clipboard tool found")
+}
@@ -0,0 +1,247 @@
+package pnl
+
+import (
+	"context"
+	"daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/history"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/tradingday"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/tradingiq/bitunix-client/bitunix"
+)
+
+const backfillDelay = 250 * time.Millisecond
+
+// BackfillProgress is reported after every trading day a backfill processed.
+type BackfillProgress struct {
+	Done  int
+	Total int
+	Date  string
+}
+
+// Backfill rebuilds the history from the exchange's position history, one
+// trading day at a time. Days are bucketed with Calendar, so they match the
+// configured timezone and day start hour.
+type Backfill struct {
+	ApiClient bitunix.ApiClient
+	Calendar  tradingday.Calendar
+	Store     *history.Store
+	Converter *Converter
+	Currency  string
+
+	// MissingOnly skips days that are already in the history.
+	MissingOnly bool
+	// StatePath, when set, records the next day to fetch so an interrupted
+	// run resumes where it stopped.
+	StatePath string
+	Progress  func(BackfillProgress)
+}
+
+type backfillState struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Next string `json:"next"`
+}
+
+// Run fetches every trading day from first to last and replaces its record
+// in the history, so running it twice gives the same result. It returns the
+// number of days written.
+func (b *Backfill) Run(ctx context.Context, first, last string, restart bool) (int, error) {
+	log := logger.Component(logger.ComponentPnl)
+
+	dates := tradingday.Dates(first, last)
+	resume := b.resumeFrom(first, last, restart)
+
+	filled := 0
+	for i, date := range dates {
+		if date < resume {
+			continue
+		}
+
+		if _, ok := b.Store.Day(date); !ok || !b.MissingOnly {
+			if err := b.fetchDay(ctx, date); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+
+		b.saveState(backfillState{From: first, To: last, Next: nextDate(date)})
+		if b.Progress != nil {
+			b.Progress(BackfillProgress{Done: i + 1, Total: len(dates), Date: date})
+		}
+
+		select {
+		case <-ctx.Done():
+			return filled, ctx.Err()
+		case <-time.After(backfillDelay):
+		}
+	}
+
+	if b.StatePath != "" {
+		if err := os.Remove(b.StatePath); err != nil && !os.IsNotExist(err) {
+			log.Warning("failed to remove backfill state: %v", err)
+		}
+	}
+
+	return filled, nil
+}
+
+func nextDate(date string) string {
+	day, err := time.Parse(tradingday.DateFormat, date)
+	if err != nil {
+		return date
+	}
+
+	return day.AddDate(0, 0, 1).Format(tradingday.DateFormat)
+}
+
+func (b *Backfill) fetchDay(ctx context.Context, date string) error {
+	log := logger.Component(logger.ComponentPnl)
+
+	start, end, err := b.Calendar.Bounds(date)
+	if err != nil {
+		return err
+	}
+
+	positions, err := fetchBalance(ctx, start, end, b.ApiClient)
+	if err != nil {
+		return err
+	}
+
+	amounts := perCoin(positions)
+	total, err := b.Converter.Total(ctx, amounts, b.Currency)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := b.Store.Update(date, func(day *history.Day) {
+		day.RealizedPnl = total
+		day.Currency = b.Currency
+		day.PerCoin = amounts
+		day.Positions = positions
+	}); err != nil {
+		return err
+	}
+
+	log.Debug("backfilled %s: %.2f %s from %d positions", date, total, b.Currency, len(positions))
+	return nil
+}
+
+// resumeFrom returns the first date still to fetch. A saved state is only
+// used when it belongs to the same range.
+func (b *Backfill) resumeFrom(first, last string, restart bool) string {
+	if b.StatePath == "" || restart {
+		return first
+	}
+
+	data, err := os.ReadFile(b.StatePath)
+	if err != nil {
+		return first
+	}
+
+	var state backfillState
+	if err := json.Unmarshal(data, &state); err != nil || state.From != first || state.To != last {
+		return first
+	}
+
+	logger.Component(logger.ComponentPnl).Info("resuming backfill at %s", state.Next)
+	return state.Next
+}
+
+func (b *Backfill) saveState(state backfillState) {
+	if b.StatePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+
+	if err := os.WriteFile(b.StatePath, data, 0600); err != nil {
+		logger.Component(logger.ComponentPnl).Warning("failed to save backfill state: %v", err)
+	}
+}
+
+// missingDaysFetched is set once backfillMissingDays completed, so restarts of
+// the tracker do not scan the history again; days after that are tracked live.
+var missingDaysFetched atomic.Bool
+
+// backfillMissingDays fetches the realized P&L of every trading day between
+// first and last that is not yet in the history. It only runs until it
+// succeeded once per process.
+func backfillMissingDays(ctx context.Context, apiClient bitunix.ApiClient, calendar tradingday.Calendar, store *history.Store, converter *Converter, currency string, first, last string) (int, error) {
+	if missingDaysFetched.Load() {
+		return 0, nil
+	}
+
+	backfill := Backfill{
+		ApiClient:   apiClient,
+		Calendar:    calendar,
+		Store:       store,
+		Converter:   converter,
+		Currency:    currency,
+		MissingOnly: true,
+	}
+
+	filled, err := backfill.Run(ctx, first, last, false)
+	if err == nil {
+		missingDaysFetched.Store(true)
+	}
+
+	return filled, err
+}
+
+// RunBackfill rebuilds the history between from and to with the configured
+// credentials. The range ends at the last completed trading day at the latest.
+func RunBackfill(ctx context.Context, cfg *config.Config, from, to string, restart bool, progress func(BackfillProgress)) (int, error) {
+	calendar := cfg.Calendar()
+
+	yesterday := calendar.Date(calendar.DayStart(time.Now()).Add(-time.Second))
+	if to == "" || to > yesterday {
+		to = yesterday
+	}
+
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse(tradingday.DateFormat, date); err != nil {
+			return 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if from > to {
+		return 0, errors.New("the start date must be before the last completed trading day")
+	}
+
+	cfg.Mtx.Lock()
+	apiClient, err := bitunix.NewApiClient(cfg.ApiKey, cfg.SecretKey)
+	cfg.Mtx.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	store, err := history.Open(app.GetHistoryPath())
+	if err != nil {
+		return 0, err
+	}
+
+	backfill := Backfill{
+		ApiClient: apiClient,
+		Calendar:  calendar,
+		Store:     store,
+		Converter: NewConverter(),
+		Currency:  cfg.Currency(),
+		StatePath: app.GetBackfillStatePath(),
+		Progress:  progress,
+	}
+
+	return backfill.Run(ctx, from, to, restart)
+}
@@ -0,0 +1,138 @@
+package pnl
+
+import (
+	"context"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/ui"
+	"fmt"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+type backfillResult struct {
+	filled int
+	err    error
+}
+
+func RunBackfillWindow(w *app.Window, cfg *config.Config, log *logger.Logger) error {
+	th := material.NewTheme()
+
+	var (
+		fromInput    widget.Editor
+		toInput      widget.Editor
+		restart      widget.Bool
+		startButton  widget.Clickable
+		cancelButton widget.Clickable
+		closeButton  widget.Clickable
+	)
+
+	fromInput.SingleLine = true
+	toInput.SingleLine = true
+
+	calendar := cfg.Calendar()
+	fromInput.SetText(calendar.YearStartDate(time.Now()))
+
+	var (
+		status   string
+		progress float32
+		cancel   context.CancelFunc
+	)
+
+	updates := make(chan BackfillProgress, 16)
+	results := make(chan backfillResult, 1)
+
+	start := func() {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+
+		from := strings.TrimSpace(fromInput.Text())
+		to := strings.TrimSpace(toInput.Text())
+		status = "Backfilling..."
+		progress = 0
+
+		go func() {
+			filled, err := RunBackfill(ctx, cfg, from, to, restart.Value, func(p BackfillProgress) {
+				select {
+				case updates <- p:
+				default:
+				}
+				w.Invalidate()
+			})
+			if err != nil {
+				log.Error("backfill failed: %v", err)
+			}
+
+			results <- backfillResult{filled: filled, err: err}
+			w.Invalidate()
+		}()
+	}
+
+	backfillHandler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
+		th := theme.(*material.Theme)
+
+		ui.CloseButtonHandler(&closeButton, gtx, closeRequested)
+
+	drain:
+		for {
+			select {
+			case update := <-updates:
+				progress = float32(update.Done) / float32(update.Total)
+				status = fmt.Sprintf("%s (%d of %d days)", update.Date, update.Done, update.Total)
+			default:
+				break drain
+			}
+		}
+
+		select {
+		case result := <-results:
+			cancel()
+			cancel = nil
+			if result.err != nil {
+				status = fmt.Sprintf("Stopped after %d days: %v. Start again to resume.", result.filled, result.err)
+				break
+			}
+			progress = 1
+			status = fmt.Sprintf("Backfilled %d trading days", result.filled)
+		default:
+		}
+
+		if startButton.Clicked(gtx) && cancel == nil {
+			start()
+		}
+
+		if cancelButton.Clicked(gtx) && cancel != nil {
+			cancel()
+		}
+
+		progressBar := func(gtx layout.Context) layout.Dimensions {
+			return ui.CommonInsets.Field.Layout(gtx, material.ProgressBar(th, progress).Layout)
+		}
+
+		return ui.VerticalLayout(gtx,
+			ui.Title(th, "Backfill History"),
+			ui.NewLabeledInput(th, "From:", "YYYY-MM-DD", &fromInput).Layout,
+			ui.NewLabeledInput(th, "To (optional):", "Yesterday", &toInput).Layout,
+			ui.CheckBox(th, &restart, "Start over instead of resuming"),
+			ui.CenteredButton(th, &startButton, "Start"),
+			ui.CenteredButton(th, &cancelButton, "Cancel"),
+			progressBar,
+			ui.StatusText(th, status),
+			ui.Spacer(unit.Dp(15)),
+			ui.CenteredButton(th, &closeButton, "Close"),
+		)
+	}
+
+	err := ui.RunWindow(w, backfillHandler, th)
+	if cancel != nil {
+		cancel()
+	}
+
+	return err
+}
@@ -0,0 +1,102 @@
+package pnl
+
+import (
+	"context"
+	"daily-profit-and-loss/internal/history"
+	"daily-profit-and-loss/internal/logger"
+	"slices"
+	"strings"
+
+	"github.com/tradingiq/bitunix-client/bitunix"
+	"github.com/tradingiq/bitunix-client/model"
+)
+
+// defaultMarginCoins are always checked for balances, in addition to the
+// coins positions were traded in today.
+var defaultMarginCoins = []string{"USDT", "USDC"}
+
+// fetchEquity returns the account equity in currency: wallet balance
+// including margin in use and unrealized P&L of open positions, summed over
+// all margin coins. The unrealized P&L is returned separately as well.
+func fetchEquity(ctx context.Context, apiClient bitunix.ApiClient, converter *Converter, currency string, coins []string) (float64, float64, error) {
+	log := logger.Component(logger.ComponentPnl)
+
+	balances := make(map[string]float64)
+	unrealized := make(map[string]float64)
+	var lastErr error
+
+	for _, coin := range coins {
+		response, err := apiClient.GetAccountBalance(ctx, model.AccountBalanceParams{MarginCoin: model.ParseMarginCoin(strings.ToLower(coin))})
+		if err != nil {
+			log.Debug("no %s balance available: %v", coin, err)
+			lastErr = err
+			continue
+		}
+
+		account := response.Data
+		unrealized[coin] = account.CrossUnrealizedPNL + account.IsolationUnrealizedPNL
+		balances[coin] = account.Available + account.Frozen + account.Margin + unrealized[coin]
+	}
+
+	if len(balances) == 0 {
+		return 0, 0, lastErr
+	}
+
+	equity, err := converter.Total(ctx, balances, currency)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	unrealizedTotal, err := converter.Total(ctx, unrealized, currency)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return equity, unrealizedTotal, nil
+}
+
+// marginCoins are the coins to query balances for.
+func (p *ProfitAndLoss) marginCoins() []string {
+	coins := append([]string(nil), defaultMarginCoins...)
+	for coin := range p.perCoin {
+		if !slices.Contains(coins, coin) {
+			coins = append(coins, coin)
+		}
+	}
+
+	return coins
+}
+
+// startingBalance returns the equity at the start of the trading day. It is
+// captured once per day and kept in the history; when tracking starts in the
+// middle of the day the P&L realized so far is taken out of the current
+// equity.
+func (p *ProfitAndLoss) loadStartingBalance(ctx context.Context) float64 {
+	log := logger.Component(logger.ComponentPnl)
+
+	if day, ok := p.history.Day(p.date()); ok && day.StartingBalance > 0 && day.Currency == p.currency {
+		return day.StartingBalance
+	}
+
+	equity, _, err := fetchEquity(ctx, p.apiClient, p.converter, p.currency, p.marginCoins())
+	if err != nil {
+		log.Warning("failed to fetch account balance, percentage P&L is unavailable: %v", err)
+		return 0
+	}
+
+	starting := equity - p.realizedPnl
+	if starting <= 0 {
+		log.Warning("account balance is not above today's P&L, percentage P&L is unavailable")
+		return 0
+	}
+
+	if err := p.history.Update(p.date(), func(day *history.Day) {
+		day.StartingBalance = starting
+		day.Currency = p.currency
+	}); err != nil {
+		log.Warning("failed to store starting balance: %v", err)
+	}
+
+	log.Debug("starting balance for %s stored", p.date())
+	return starting
+}
@@ -0,0 +1,191 @@
+package pnl
+
+import (
+	"context"
+	"daily-profit-and-loss/internal/logger"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tickerURL      = "https://fapi.bitunix.com/api/v1/futures/market/tickers"
+	priceCacheTime = time.Minute
+)
+
+// stablecoins are treated as equal in value when the exchange has no market
+// between them.
+var stablecoins = map[string]bool{"USDT": true, "USDC": true, "USD": true}
+
+// marginCoin derives the settlement coin of a futures symbol. Linear
+// contracts are margined in their quote coin (BTCUSDT, ETHUSDC), inverse
+// contracts quoted in USD are margined in their base coin (BTCUSD).
+func marginCoin(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+
+	for _, quote := range []string{"USDT", "USDC"} {
+		if strings.HasSuffix(symbol, quote) {
+			return quote
+		}
+	}
+
+	if base, ok := strings.CutSuffix(symbol, "USD"); ok && base != "" {
+		return base
+	}
+
+	return "USDT"
+}
+
+// formatAmounts renders per coin amounts, e.g. "12.50 USDT, -3.00 USDC".
+func formatAmounts(amounts map[string]float64) string {
+	coins := make([]string, 0, len(amounts))
+	for coin := range amounts {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+
+	parts := make([]string, 0, len(coins))
+	for _, coin := range coins {
+		parts = append(parts, fmt.Sprintf("%.2f %s", amounts[coin], coin))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+type cachedPrice struct {
+	price   float64
+	fetched time.Time
+}
+
+// Converter converts amounts between coins using the last prices of the
+// exchange's futures markets.
+type Converter struct {
+	client *http.Client
+	mtx    sync.Mutex
+	cache  map[string]cachedPrice
+}
+
+func NewConverter() *Converter {
+	return &Converter{
+		client: &http.Client{Timeout: 5 * time.Second},
+		cache:  make(map[string]cachedPrice),
+	}
+}
+
+// Total converts amounts held in different coins into currency and sums them.
+func (c *Converter) Total(ctx context.Context, amounts map[string]float64, currency string) (float64, error) {
+	total := 0.0
+	for coin, amount := range amounts {
+		if amount == 0 {
+			continue
+		}
+
+		rate, err := c.Rate(ctx, coin, currency)
+		if err != nil {
+			return 0, err
+		}
+		total += amount * rate
+	}
+
+	return total, nil
+}
+
+// Rate returns how much one unit of from is worth in to.
+func (c *Converter) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+
+	if price, err := c.price(ctx, from+to); err == nil {
+		return price, nil
+	}
+
+	if price, err := c.price(ctx, to+from); err == nil && price > 0 {
+		return 1 / price, nil
+	}
+
+	// Convert through USDT, which has the most markets.
+	if from != "USDT" && to != "USDT" {
+		fromRate, err := c.Rate(ctx, from, "USDT")
+		if err == nil {
+			toRate, err := c.Rate(ctx, to, "USDT")
+			if err == nil && toRate > 0 {
+				return fromRate / toRate, nil
+			}
+		}
+	}
+
+	if stablecoins[from] && stablecoins[to] {
+		logger.Component(logger.ComponentPnl).Debug("no market between %s and %s, treating them as equal", from, to)
+		return 1, nil
+	}
+
+	return 0, fmt.Errorf("no price available to convert %s to %s", from, to)
+}
+
+type tickerResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		Symbol    string      `json:"symbol"`
+		LastPrice json.Number `json:"lastPrice"`
+	} `json:"data"`
+}
+
+func (c *Converter) price(ctx context.Context, symbol string) (float64, error) {
+	c.mtx.Lock()
+	cached, ok := c.cache[symbol]
+	c.mtx.Unlock()
+	if ok && time.Since(cached.fetched) < priceCacheTime {
+		return cached.price, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tickerURL+"?symbols="+url.QueryEscape(symbol), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch price of %s: %w", symbol, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to fetch price of %s: %s", symbol, resp.Status)
+	}
+
+	var tickers tickerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tickers); err != nil {
+		return 0, fmt.Errorf("failed to parse price of %s: %w", symbol, err)
+	}
+	if tickers.Code != 0 {
+		return 0, fmt.Errorf("failed to fetch price of %s: %s", symbol, tickers.Msg)
+	}
+
+	for _, ticker := range tickers.Data {
+		if !strings.EqualFold(ticker.Symbol, symbol) {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(ticker.LastPrice.String(), 64)
+		if err != nil || price <= 0 {
+			return 0, fmt.Errorf("invalid price for %s: %q", symbol, ticker.LastPrice)
+		}
+
+		c.mtx.Lock()
+		c.cache[symbol] = cachedPrice{price: price, fetched: time.Now()}
+		c.mtx.Unlock()
+
+		return price, nil
+	}
+
+	return 0, fmt.Errorf("no market for %s", symbol)
+}
@@ -0,0 +1,53 @@
+package pnl
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const hiddenValue = "hidden"
+
+// amountPattern matches decimal numbers such as amounts and percentages.
+var amountPattern = regexp.MustCompile(`[-+]?\d+\.\d+%?`)
+
+// hideAmounts replaces the amounts in text while streamer mode is on.
+// Percentages are kept, as they are shown in streamer mode anyway.
+func hideAmounts(cfg *config.Config, text string) string {
+	if cfg == nil || !cfg.IsStreamerMode() {
+		return text
+	}
+
+	return amountPattern.ReplaceAllStringFunc(text, func(amount string) string {
+		if strings.HasSuffix(amount, "%") {
+			return amount
+		}
+		return hiddenValue
+	})
+}
+
+// formatPnl renders a P&L value for windows and the tray, together with its
+// percentage of the starting balance when that is known. In streamer mode
+// only the percentage is shown.
+func formatPnl(cfg *config.Config, value float64, startingBalance float64) string {
+	streamer := cfg != nil && cfg.IsStreamerMode()
+	currency := config.DefaultReportingCurrency
+	if cfg != nil {
+		currency = cfg.Currency()
+	}
+
+	if startingBalance <= 0 {
+		if streamer {
+			return hiddenValue
+		}
+		return fmt.Sprintf("%.2f %s", value, currency)
+	}
+
+	percent := fmt.Sprintf("%+.2f%%", value/startingBalance*100)
+	if streamer {
+		return percent
+	}
+
+	return fmt.Sprintf("%.2f %s (%s)", value, currency, percent)
+}
@@ -0,0 +1,88 @@
+package pnl
+
+import (
+	app2 "daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/trayicon"
+	"fmt"
+	"math"
+
+	"github.com/getlantern/systray"
+)
+
+// setIcon renders the tray icon for the given options, using the configured
+// style.
+func setIcon(cfg *config.Config, opts trayicon.Options) {
+	opts.Style = cfg.IconStyle()
+	if opts.Style == trayicon.StyleStatic {
+		systray.SetIcon(app2.Icon)
+		return
+	}
+
+	icon, err := trayicon.Render(opts)
+	if err != nil {
+		logger.Component(logger.ComponentPnl).Warning("failed to render tray icon: %v", err)
+		return
+	}
+
+	systray.SetIcon(icon)
+}
+
+// setTrayState shows a tracker state without P&L in the tray icon and
+// resets the title.
+func setTrayState(cfg *config.Config, state trayicon.State) {
+	setIcon(cfg, trayicon.Options{State: state})
+	trayTitle.set(appTitle, appTitle)
+	updateLive(func(l *Live) {
+		l.State = state
+		l.HasUnrealized = false
+	})
+}
+
+// updateIcon shows the current P&L in the tray icon. The caller must hold
+// p.mtx.
+func (p *ProfitAndLoss) updateIcon() {
+	if p.config == nil {
+		return
+	}
+
+	setIcon(p.config, trayicon.Options{
+		State:        trayicon.Running,
+		Pnl:          p.realizedPnl,
+		Label:        p.iconLabel(),
+		LossProgress: p.lossProgress(),
+	})
+}
+
+// iconLabel is the rounded P&L, or the rounded percentage in streamer mode.
+func (p *ProfitAndLoss) iconLabel() string {
+	if !p.config.IsStreamerMode() {
+		return trayicon.Abbreviate(p.realizedPnl)
+	}
+
+	if p.startingBalance <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s%%", trayicon.Abbreviate(p.realizedPnl/p.startingBalance*100))
+}
+
+// lossProgress is the share of the daily loss limit used so far, from 0 to 1.
+func (p *ProfitAndLoss) lossProgress() float64 {
+	if p.realizedPnl >= 0 {
+		return 0
+	}
+
+	maxLoss, maxLossPercent := p.config.LossLimits()
+
+	progress := 0.0
+	if maxLoss > 0 {
+		progress = -p.realizedPnl / maxLoss
+	}
+	if maxLossPercent > 0 && p.startingBalance > 0 {
+		progress = math.Max(progress, -p.realizedPnl/p.startingBalance*100/maxLossPercent)
+	}
+
+	return math.Min(progress, 1)
+}
@@ -0,0 +1,117 @@
+package pnl
+
+import (
+	"context"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/trayicon"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// unrealizedInterval is how often the unrealized P&L of open positions is
+// fetched while tracking.
+const unrealizedInterval = 15 * time.Second
+
+// Live is the latest state of the tracker, as shown by the overlay.
+type Live struct {
+	State    trayicon.State
+	Snapshot Snapshot
+	// Unrealized is the P&L of open positions in the reporting currency.
+	Unrealized    float64
+	HasUnrealized bool
+	LossProgress  float64
+	// LossRemaining is the amount left before the absolute loss limit is
+	// reached; HasLossLimit is false when no absolute limit is configured.
+	LossRemaining float64
+	HasLossLimit  bool
+	Updated       time.Time
+}
+
+var live struct {
+	mtx   sync.Mutex
+	value Live
+}
+
+// unrealizedViewers counts the open windows showing the unrealized P&L; it is
+// only fetched while there is one. unrealizedWake starts a fetch right away.
+var (
+	unrealizedViewers atomic.Int32
+	unrealizedWake    = make(chan struct{}, 1)
+)
+
+// showUnrealized registers a viewer of the unrealized P&L until the returned
+// function is called.
+func showUnrealized() func() {
+	unrealizedViewers.Add(1)
+	select {
+	case unrealizedWake <- struct{}{}:
+	default:
+	}
+
+	return func() { unrealizedViewers.Add(-1) }
+}
+
+// CurrentState returns the latest state of the tracker.
+func CurrentState() Live {
+	live.mtx.Lock()
+	defer live.mtx.Unlock()
+
+	return live.value
+}
+
+func updateLive(update func(l *Live)) {
+	live.mtx.Lock()
+	defer live.mtx.Unlock()
+
+	update(&live.value)
+	live.value.Updated = time.Now()
+}
+
+// publishLive stores the current figures. The caller must hold p.mtx.
+func (p *ProfitAndLoss) publishLive(snapshot Snapshot) {
+	maxLoss, _ := p.config.LossLimits()
+	progress := p.lossProgress()
+
+	updateLive(func(l *Live) {
+		l.State = trayicon.Running
+		l.Snapshot = snapshot
+		l.LossProgress = progress
+		l.HasLossLimit = maxLoss > 0
+		l.LossRemaining = max(maxLoss+snapshot.Today, 0)
+	})
+}
+
+// watchUnrealized fetches the unrealized P&L periodically while a window
+// shows it, until ctx is done.
+func (p *ProfitAndLoss) watchUnrealized(ctx context.Context) {
+	log := logger.Component(logger.ComponentPnl)
+
+	ticker := time.NewTicker(unrealizedInterval)
+	defer ticker.Stop()
+
+	for {
+		if unrealizedViewers.Load() > 0 {
+			p.mtx.Lock()
+			coins := p.marginCoins()
+			p.mtx.Unlock()
+
+			_, unrealized, err := fetchEquity(ctx, p.apiClient, p.converter, p.currency, coins)
+			if err != nil {
+				log.Debug("failed to fetch unrealized pnl: %v", err)
+			}
+
+			updateLive(func(l *Live) {
+				l.Unrealized = unrealized
+				l.HasUnrealized = err == nil
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-unrealizedWake:
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package pnl
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/diagnostics"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/picker"
+	"daily-profit-and-loss/internal/ui"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// RunLogWindow shows the log entries of the running application. Entries can
+// be filtered by minimum level and text, copied to the clipboard and saved
+// together with the log files as a diagnostic bundle. Amounts are hidden in
+// streamer mode.
+func RunLogWindow(w *app.Window, cfg *config.Config, log *logger.Logger) error {
+	th := material.NewTheme()
+
+	var (
+		level        widget.Enum
+		searchInput  widget.Editor
+		autoScroll   widget.Bool
+		copyButton   widget.Clickable
+		bundleButton widget.Clickable
+		openButton   widget.Clickable
+		closeButton  widget.Clickable
+		list         = widget.List{List: layout.List{Axis: layout.Vertical}}
+	)
+
+	level.Value = logger.Debug.String()
+	searchInput.SingleLine = true
+	autoScroll.Value = true
+
+	var status string
+	statuses := make(chan string, 1)
+
+	all, updates, cancel := log.Follow(256)
+	defer cancel()
+
+	var (
+		pendingMtx sync.Mutex
+		pending    []logger.LogEntry
+	)
+
+	go func() {
+		for entry := range updates {
+			pendingMtx.Lock()
+			pending = append(pending, entry)
+			pendingMtx.Unlock()
+
+			w.Invalidate()
+		}
+	}()
+
+	filter := func() []logger.LogEntry {
+		minimum := logger.Debug
+		for _, l := range logger.Levels {
+			if l.String() == level.Value {
+				minimum = l
+			}
+		}
+		search := strings.ToLower(strings.TrimSpace(searchInput.Text()))
+
+		var entries []logger.LogEntry
+		for _, entry := range all {
+			if entry.Level < minimum {
+				continue
+			}
+			if search != "" && !strings.Contains(strings.ToLower(hideAmounts(cfg, entry.Message)), search) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+
+		return entries
+	}
+
+	saveBundle := func() {
+		go func() {
+			path, err := picker.New().Pick(picker.Options{
+				Title:       "Save diagnostic bundle",
+				Mode:        picker.ModeFile,
+				Filters:     []picker.Filter{{Name: "Zip archives", Extensions: []string{"zip"}}},
+				DefaultName: fmt.Sprintf("daily-pnl-diagnostics-%s.zip", time.Now().Format("2006-01-02-150405")),
+			})
+
+			switch {
+			case errors.Is(err, picker.ErrCanceled):
+				return
+			case err != nil:
+				log.Error("error showing picker: %v", err)
+				statuses <- fmt.Sprintf("Could not choose a file: %v", err)
+			default:
+				if err := diagnostics.WriteBundle(path, cfg, log); err != nil {
+					log.Error("failed to write diagnostic bundle: %v", err)
+					statuses <- fmt.Sprintf("Could not save the bundle: %v", err)
+				} else {
+					log.Info("diagnostic bundle saved to %s", path)
+					statuses <- "Diagnostic bundle saved to " + path
+				}
+			}
+			w.Invalidate()
+		}()
+	}
+
+	logHandler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
+		th := theme.(*material.Theme)
+
+		ui.CloseButtonHandler(&closeButton, gtx, closeRequested)
+
+		select {
+		case status = <-statuses:
+		default:
+		}
+
+		pendingMtx.Lock()
+		all = append(all, pending...)
+		pending = nil
+		pendingMtx.Unlock()
+
+		if capacity := log.Capacity(); len(all) > capacity {
+			all = append([]logger.LogEntry(nil), all[len(all)-capacity:]...)
+		}
+
+		entries := filter()
+
+		if copyButton.Clicked(gtx) {
+			lines := make([]string, len(entries))
+			for i, entry := range entries {
+				lines[i] = hideAmounts(cfg, entry.String())
+			}
+			gtx.Execute(clipboard.WriteCmd{Type: "application/text", Data: io.NopCloser(strings.NewReader(strings.Join(lines, "\n")))})
+			status = fmt.Sprintf("Copied %d entries", len(entries))
+		}
+		if bundleButton.Clicked(gtx) {
+			saveBundle()
+		}
+		if openButton.Clicked(gtx) {
+			OpenLogFile(log)
+		}
+
+		list.ScrollToEnd = autoScroll.Value
+
+		levels := func(gtx layout.Context) layout.Dimensions {
+			children := make([]layout.FlexChild, 0, len(logger.Levels))
+			for _, l := range logger.Levels {
+				children = append(children, layout.Rigid(material.RadioButton(th, &level, l.String(), l.String()).Layout))
+			}
+			return ui.CommonInsets.Label.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+			})
+		}
+
+		buttons := func(gtx layout.Context) layout.Dimensions {
+			return ui.CommonInsets.Button.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Spacing: layout.SpaceEvenly}.Layout(gtx,
+					layout.Rigid(material.Button(th, &copyButton, "Copy").Layout),
+					layout.Rigid(material.Button(th, &bundleButton, "Save Diagnostic Bundle").Layout),
+					layout.Rigid(material.Button(th, &openButton, "Open Log File").Layout),
+					layout.Rigid(material.Button(th, &closeButton, "Close").Layout),
+				)
+			})
+		}
+
+		lines := func(gtx layout.Context) layout.Dimensions {
+			return ui.CommonInsets.Field.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return material.List(th, &list).Layout(gtx, len(entries), func(gtx layout.Context, i int) layout.Dimensions {
+					label := material.Body2(th, hideAmounts(cfg, entries[i].String()))
+					if entries[i].Level >= logger.Warning {
+						label.Color = ui.ErrorColor
+					}
+					return layout.Inset{Bottom: unit.Dp(2)}.Layout(gtx, label.Layout)
+				})
+			})
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(levels),
+			layout.Rigid(ui.NewLabeledInput(th, "Search:", "Filter messages", &searchInput).Layout),
+			layout.Rigid(ui.CheckBox(th, &autoScroll, "Auto-scroll")),
+			layout.Flexed(1, lines),
+			layout.Rigid(ui.StatusText(th, status)),
+			layout.Rigid(buttons),
+		)
+	}
+
+	return ui.RunWindow(w, logHandler, th)
+}
@@ -0,0 +1,153 @@
+package pnl
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/trayicon"
+	"daily-profit-and-loss/internal/ui"
+	"fmt"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+const (
+	overlayRefreshInterval = time.Second
+	// overlaySaveDelay is how long the overlay must stay in place before its
+	// new position is saved, so dragging does not write the configuration
+	// continuously.
+	overlaySaveDelay = 2 * time.Second
+)
+
+// RunOverlayWindow shows the compact P&L overlay. The whole window can be
+// dragged; where it was left is stored in the configuration.
+func RunOverlayWindow(w *app.Window, cfg *config.Config, log *logger.Logger) error {
+	th := material.NewTheme()
+	th.TextSize = unit.Sp(13)
+
+	defer showUnrealized()()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(overlayRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.Invalidate()
+			}
+		}
+	}()
+
+	var (
+		view    app.ViewEvent
+		movedAt time.Time
+	)
+
+	savePosition := func() {
+		movedAt = time.Time{}
+		if err := config.SaveConfig(cfg); err != nil {
+			log.Warning("failed to save overlay position: %v", err)
+		}
+	}
+
+	onView := func(e app.ViewEvent) {
+		view = e
+
+		cfg.Mtx.Lock()
+		saved := cfg.OverlayPosition
+		cfg.Mtx.Unlock()
+
+		if saved != nil {
+			ui.KeepOnTop(e, saved.X, saved.Y, true)
+		} else {
+			ui.KeepOnTop(e, 0, 0, false)
+		}
+	}
+
+	line := func(th *material.Theme, text string, value float64) layout.Widget {
+		return func(gtx layout.Context) layout.Dimensions {
+			label := material.Body1(th, text)
+			label.Color = ui.PnlColor(th, value)
+			return layout.Inset{Left: unit.Dp(8), Right: unit.Dp(8), Top: unit.Dp(2), Bottom: unit.Dp(2)}.Layout(gtx, label.Layout)
+		}
+	}
+
+	overlayHandler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
+		th := theme.(*material.Theme)
+
+		if view != nil {
+			if x, y, ok := ui.WindowPosition(view); ok {
+				position := config.WindowPosition{X: x, Y: y}
+
+				cfg.Mtx.Lock()
+				moved := cfg.OverlayPosition == nil || *cfg.OverlayPosition != position
+				if moved {
+					cfg.OverlayPosition = &position
+				}
+				cfg.Mtx.Unlock()
+
+				if moved {
+					movedAt = gtx.Now
+				}
+			}
+		}
+		if !movedAt.IsZero() && gtx.Now.Sub(movedAt) >= overlaySaveDelay {
+			savePosition()
+		}
+
+		area := clip.Rect{Max: gtx.Constraints.Max}.Push(gtx.Ops)
+		system.ActionInputOp(system.ActionMove).Add(gtx.Ops)
+		area.Pop()
+
+		state := CurrentState()
+		snapshot := state.Snapshot
+
+		unrealized := "Unrealized: -"
+		if state.HasUnrealized {
+			unrealized = fmt.Sprintf("Unrealized: %s", formatPnl(cfg, state.Unrealized, snapshot.StartingBalance))
+		}
+
+		limit := "Loss limit: none"
+		switch {
+		case state.HasLossLimit && !cfg.IsStreamerMode():
+			limit = fmt.Sprintf("Loss limit: %.2f %s left", state.LossRemaining, snapshot.Currency)
+		case state.LossProgress > 0 || state.HasLossLimit:
+			limit = fmt.Sprintf("Loss limit: %.0f%% used", state.LossProgress*100)
+		}
+
+		connection := "Inactive"
+		switch state.State {
+		case trayicon.Running:
+			connection = "Connected"
+		case trayicon.Paused:
+			connection = "Paused"
+		case trayicon.Failed:
+			connection = "Connection error"
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(line(th, fmt.Sprintf("Realized: %s", formatPnl(cfg, snapshot.Today, snapshot.StartingBalance)), snapshot.Today)),
+			layout.Rigid(line(th, unrealized, state.Unrealized)),
+			layout.Rigid(line(th, limit, 0)),
+			layout.Rigid(line(th, connection, 0)),
+		)
+	}
+
+	err := ui.RunWindowWithView(w, overlayHandler, th, onView)
+	if !movedAt.IsZero() {
+		savePosition()
+	}
+
+	return err
+}
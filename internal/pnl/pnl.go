@@ -2,8 +2,12 @@ package pnl
 
 import (
 	"context"
+	app2 "daily-profit-and-loss/internal/app"
 	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/history"
 	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/tradingday"
+	"daily-profit-and-loss/internal/trayicon"
 	"errors"
 	"fmt"
 	"github.com/gen2brain/beeep"
@@ -17,191 +21,461 @@ import (
 	"time"
 )
 
-func RunPnl(ctx context.Context, cfg *config.Config, mStatus *systray.MenuItem) {
-	errChan := make(chan error)
-	log := logger.GetInstance()
+// Command is an action requested from the tray menu.
+type Command int
+
+const (
+	// CommandRefresh reconciles the P&L with the exchange.
+	CommandRefresh Command = iota
+	CommandPause
+	CommandResume
+	// CommandReconnect restarts tracking with a new connection.
+	CommandReconnect
+)
+
+func RunPnl(ctx context.Context, cfg *config.Config, mStatus *systray.MenuItem, commands <-chan Command) {
+	errChan := make(chan error, 1)
+	refresh := make(chan struct{}, 1)
+	log := logger.Component(logger.ComponentPnl)
+
+	store, err := history.Open(app2.GetHistoryPath())
+	if err != nil {
+		log.Error("failed to load pnl history: %v", err)
+	}
+	defer func() {
+		if err := store.Flush(); err != nil {
+			log.Warning("failed to flush pnl history: %v", err)
+		}
+	}()
+
+	menu := newStatusMenu(mStatus)
+	setTrayState(cfg, trayicon.Inactive)
+
+	var wg sync.WaitGroup
 
 	for {
-		ctx, cancel := context.WithCancel(ctx)
+		trackCtx, cancel := context.WithCancel(ctx)
 
-		berlin, err := time.LoadLocation("Europe/Berlin")
-		if err != nil {
-			log.Warning("failed to load Europe/Berlin timezone: %v", err)
-			log.Debug("falling back to fixed GMT+1 offset")
+		stop := func() {
+			cancel()
+			wg.Wait()
 
-			berlin = time.FixedZone("GMT+1", 3600)
+			select {
+			case <-errChan:
+			default:
+			}
 		}
 
+		calendar := cfg.Calendar()
+
 		if cfg.SecretKey != "" && cfg.ApiKey != "" {
 			err := beeep.Notify("TradingIQ PNL Tracker", "PNL Tracking Started", "assets/information.png")
 			if err != nil {
 				log.Warning("Could not notify about start of pnl tracking: %v", err)
 			}
-			go track(ctx, berlin, cfg, errChan, log, mStatus)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				track(trackCtx, calendar, cfg, store, menu, refresh, errChan, log, mStatus)
+			}()
 		}
 
 		now := time.Now()
-		nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-		duration := nextMidnight.Sub(now)
+		nextDay := calendar.NextDayStart(now)
+		duration := nextDay.Sub(now)
 		firstTick := time.NewTimer(duration)
-
-		select {
-		case err := <-errChan:
-			if err != nil {
-				log.Error("error while pnl tracking, %v", err)
-
-				cancel()
-
-				switch {
-				case errors.Is(err, bitunix_errors.ErrAuthentication), errors.Is(err, bitunix_errors.ErrSignatureError):
-					err := beeep.Notify("TradingIQ PNL Tracker", "Authentication failed", "assets/information.png")
-					if err != nil {
-						log.Warning("Could not notify about authentication error: %v", err)
+		paused := false
+
+		for waiting := true; waiting; {
+			waiting = false
+
+			select {
+			case command := <-commands:
+				switch command {
+				case CommandRefresh:
+					select {
+					case refresh <- struct{}{}:
+					default:
 					}
-					mStatus.SetTitle("Authentication Error")
-					cfg.SecretKey = ""
-					cfg.ApiKey = ""
-
-				case errors.Is(err, bitunix_errors.ErrNetwork), errors.Is(err, bitunix_errors.ErrTimeout):
-					err := beeep.Notify("TradingIQ PNL Tracker", "Network connection failed", "assets/information.png")
-					if err != nil {
-						log.Warning("Could not notify about network error: %v", err)
-					}
-
-					mStatus.SetTitle("Timeout Error")
-
-					time.Sleep(5 * time.Minute)
+					waiting = true
+				case CommandPause:
+					log.Info("pnl tracking paused")
+					stop()
+					paused = true
+				case CommandReconnect:
+					log.Info("reconnecting pnl tracking")
+					mStatus.SetTitle("Reconnecting...")
+					setTrayState(cfg, trayicon.Inactive)
+
+					stop()
 				default:
-					mStatus.SetTitle("Error")
+					waiting = true
 				}
+			case err := <-errChan:
+				if err != nil {
+					log.Error("error while pnl tracking, %v", err)
+
+					stop()
+
+					switch {
+					case errors.Is(err, bitunix_errors.ErrAuthentication), errors.Is(err, bitunix_errors.ErrSignatureError):
+						err := beeep.Notify("TradingIQ PNL Tracker", "Authentication failed", "assets/information.png")
+						if err != nil {
+							log.Warning("Could not notify about authentication error: %v", err)
+						}
+						mStatus.SetTitle("Authentication Error")
+						setTrayState(cfg, trayicon.Failed)
+						cfg.SecretKey = ""
+						cfg.ApiKey = ""
+
+					case errors.Is(err, bitunix_errors.ErrNetwork), errors.Is(err, bitunix_errors.ErrTimeout):
+						err := beeep.Notify("TradingIQ PNL Tracker", "Network connection failed", "assets/information.png")
+						if err != nil {
+							log.Warning("Could not notify about network error: %v", err)
+						}
+
+						mStatus.SetTitle("Timeout Error")
+						setTrayState(cfg, trayicon.Failed)
+
+						paused = waitForRetry(ctx, commands, 5*time.Minute)
+					default:
+						mStatus.SetTitle("Error")
+						setTrayState(cfg, trayicon.Failed)
+					}
 
+				}
+			case <-cfg.Changed:
+				log.Debug("starting pnl tracking")
+				mStatus.SetTitle("Inactive...")
+				setTrayState(cfg, trayicon.Inactive)
+
+				stop()
+			case <-firstTick.C:
+				log.Debug("restarting pnl tracking")
+				mStatus.SetTitle("Inactive...")
+				setTrayState(cfg, trayicon.Inactive)
+
+				stop()
+			case <-ctx.Done():
+				log.Debug("exiting pnl tracking")
+
+				mStatus.SetTitle("Exiting...")
 			}
-		case <-cfg.Changed:
-			log.Debug("starting pnl tracking")
-			mStatus.SetTitle("Inactive...")
+		}
 
-			cancel()
-		case <-firstTick.C:
-			log.Debug("restarting pnl tracking")
-			mStatus.SetTitle("Inactive...")
+		firstTick.Stop()
 
-			cancel()
-		case <-ctx.Done():
-			log.Debug("exiting pnl tracking")
+		if paused {
+			mStatus.SetTitle("Paused")
+			setTrayState(cfg, trayicon.Paused)
 
-			mStatus.SetTitle("Exiting...")
+			waitForResume(ctx, commands)
+		}
+
+		if ctx.Err() != nil {
+			stop()
+			log.Debug("pnl tracking stopped")
 
-			cancel()
 			return
 		}
 	}
 
 }
 
-func track(ctx context.Context, berlin *time.Location, config *config.Config, errChan chan error, log *logger.Logger, mStatus *systray.MenuItem) {
+// waitForResume blocks until tracking is resumed or ctx is done.
+func waitForResume(ctx context.Context, commands <-chan Command) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case command := <-commands:
+			if command == CommandResume || command == CommandReconnect {
+				logger.Component(logger.ComponentPnl).Info("pnl tracking resumed")
+				return
+			}
+		}
+	}
+}
+
+// waitForRetry blocks for delay after a network error. It returns early when
+// tracking is reconnected or paused and reports whether it was paused.
+func waitForRetry(ctx context.Context, commands <-chan Command, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return false
+		case command := <-commands:
+			switch command {
+			case CommandReconnect:
+				logger.Component(logger.ComponentPnl).Info("reconnecting pnl tracking")
+				return false
+			case CommandPause:
+				logger.Component(logger.ComponentPnl).Info("pnl tracking paused")
+				return true
+			}
+		}
+	}
+}
+
+func track(ctx context.Context, calendar tradingday.Calendar, config *config.Config, store *history.Store, menu *statusMenu, refresh <-chan struct{}, errChan chan error, log *logger.Logger, mStatus *systray.MenuItem) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	apiClient, wsClient, err := initClient(ctx, config)
 	if err != nil {
 		log.Error("failed to create API client: %v", err)
-		errChan <- err
+		reportError(ctx, errChan, err)
 		return
 	}
 
-	now := time.Now().In(berlin)
-	todayMorning := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	tomorrowMorning := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+	go func() {
+		<-ctx.Done()
+		wsClient.Disconnect()
+	}()
+
+	now := time.Now()
+	todayMorning := calendar.DayStart(now)
+	tomorrowMorning := calendar.NextDayStart(now).Add(-time.Second)
 
-	realizedPnl, err := fetchBalance(ctx, todayMorning, tomorrowMorning, apiClient)
+	positions, err := fetchBalance(ctx, todayMorning, tomorrowMorning, apiClient)
 	if err != nil {
 		log.Error("failed to fetch initial balance: %v", err)
-		errChan <- err
+		reportError(ctx, errChan, err)
 		return
 	}
 
-	mStatus.SetTitle(fmt.Sprintf("Running - Todays PnL %.2f", realizedPnl))
+	pnl := NewProfitAndLoss(0, config, mStatus, apiClient, todayMorning, tomorrowMorning)
+	pnl.history = store
+	pnl.converter = NewConverter()
+	pnl.currency = config.Currency()
+	pnl.calendar = calendar
+	pnl.menu = menu
+	pnl.ctx = ctx
+	if day, ok := store.Day(pnl.date()); ok {
+		pnl.timeline = day.Timeline
+	}
 
-	log.Debug("initial balance at application start: %.2f", realizedPnl)
-	pnl := NewProfitAndLoss(realizedPnl, config, mStatus, apiClient, todayMorning, tomorrowMorning)
+	amounts, total, err := pnl.convert(ctx, positions)
+	if err != nil {
+		log.Error("failed to compute initial pnl: %v", err)
+		reportError(ctx, errChan, err)
+		return
+	}
+	defer pnl.Flush()
+
+	pnl.mtx.Lock()
+	pnl.setPositions(positions, amounts, total)
+
+	pnl.startingBalance = pnl.loadStartingBalance(ctx)
+	pnl.publish()
+	pnl.mtx.Unlock()
+
+	log.Debug("initial balance at application start: %.2f %s (%s)", pnl.realizedPnl, pnl.currency, formatAmounts(pnl.perCoin))
+
+	var workers sync.WaitGroup
+	defer func() {
+		cancel()
+		workers.Wait()
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		pnl.watchUnrealized(ctx)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-refresh:
+				log.Info("refreshing pnl")
+				pnl.reconcile(ctx)
+			}
+		}
+	}()
 
-	if config.ProfitAndLossFile != "" {
-		if err := SavePnLToFile(realizedPnl, config.ProfitAndLossFile); err != nil {
-			log.Warning("failed to save initial PnL to file: %v", err)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+
+		yesterday := calendar.Date(todayMorning.Add(-time.Second))
+		filled, err := backfillMissingDays(ctx, apiClient, calendar, store, pnl.converter, pnl.currency, calendar.YearStartDate(now), yesterday)
+		if err != nil && ctx.Err() == nil {
+			log.Warning("failed to backfill pnl history: %v", err)
 		}
-	}
+		if filled > 0 && ctx.Err() == nil {
+			log.Info("backfilled %d trading days from the exchange", filled)
 
+			pnl.mtx.Lock()
+			pnl.publish()
+			pnl.mtx.Unlock()
+		}
+	}()
+
+	wsLog := logger.Component(logger.ComponentWs)
 	if err := wsClient.SubscribePositions(pnl); err != nil {
-		log.Error("failed to subscribe to positions: %v", err)
-		errChan <- err
+		wsLog.Error("failed to subscribe to positions: %v", err)
+		reportError(ctx, errChan, err)
 		return
 	}
 
 	if err := wsClient.Stream(); err != nil {
-		if errors.Is(err, bitunix_errors.ErrConnectionClosed) {
-			log.Debug("websocket is ending")
+		if errors.Is(err, bitunix_errors.ErrConnectionClosed) || ctx.Err() != nil {
+			wsLog.Debug("websocket is ending")
 
 			return
 		} else {
-			log.Error("failed to stream positions: %v", err.Error())
+			wsLog.Error("failed to stream positions: %v", err)
 
-			errChan <- err
+			reportError(ctx, errChan, err)
 			return
 		}
 	}
 }
 
-func fetchBalance(ctx context.Context, todayMorning time.Time, tomorrowMorning time.Time, apiClient bitunix.ApiClient) (float64, error) {
+// reportError hands err to RunPnl unless the tracker is already being stopped.
+func reportError(ctx context.Context, errChan chan error, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	select {
+	case errChan <- err:
+	case <-ctx.Done():
+	}
+}
+
+// positionPageSize is the largest page the position history endpoint serves.
+const positionPageSize = 100
+
+const (
+	// pageTimeout limits a single request for a page of positions.
+	pageTimeout = 4 * time.Second
+	// fetchTimeout limits reloading all of today's positions, which may take
+	// several pages on a busy day.
+	fetchTimeout = 30 * time.Second
+)
+
+// fetchBalance returns the positions closed between todayMorning and
+// tomorrowMorning, paging through the position history.
+func fetchBalance(ctx context.Context, todayMorning time.Time, tomorrowMorning time.Time, apiClient bitunix.ApiClient) ([]history.ClosedPosition, error) {
 	params := model.PositionHistoryParams{
-		Limit:     100,
+		Limit:     positionPageSize,
 		StartTime: &todayMorning,
 		EndTime:   &tomorrowMorning,
 	}
 
-	log := logger.GetInstance()
-	posResponse, err := apiClient.GetPositionHistory(ctx, params)
-	if err != nil {
-		log.Debug("failed to fetch initial positions: %v", err)
-		return 0.0, err
+	log := logger.Component(logger.ComponentPnl)
+
+	var positions []history.ClosedPosition
+	seen := make(map[string]bool)
+
+	for {
+		pageCtx, cancel := context.WithTimeout(ctx, pageTimeout)
+		posResponse, err := apiClient.GetPositionHistory(pageCtx, params)
+		cancel()
+		if err != nil {
+			log.Debug("failed to fetch positions: %v", err)
+			return nil, err
+		}
+
+		for _, position := range posResponse.Data.Positions {
+			symbol := fmt.Sprint(position.Symbol)
+			id := fmt.Sprint(position.PositionID)
+
+			// Positions without an ID cannot be told apart from identical
+			// trades, so they are all kept.
+			if id != "" && id != "0" {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+			}
+
+			positions = append(positions, history.ClosedPosition{
+				ID:          id,
+				Symbol:      symbol,
+				Side:        fmt.Sprint(position.Side),
+				MarginCoin:  marginCoin(symbol),
+				RealizedPnl: position.RealizedPNL,
+			})
+		}
+
+		if len(posResponse.Data.Positions) < positionPageSize {
+			return positions, nil
+		}
+
+		params.Skip += positionPageSize
 	}
+}
 
-	var (
-		realizedPnl float64 = 0
-	)
-	for _, position := range posResponse.Data.Positions {
-		realizedPnl += position.RealizedPNL
+// perCoin sums the realized P&L of positions by margin coin.
+func perCoin(positions []history.ClosedPosition) map[string]float64 {
+	amounts := make(map[string]float64)
+	for _, position := range positions {
+		amounts[position.MarginCoin] += position.RealizedPnl
 	}
-	return realizedPnl, nil
+
+	return amounts
 }
 
 func initClient(ctx context.Context, config *config.Config) (bitunix.ApiClient, bitunix.PrivateWebsocketClient, error) {
 	config.Mtx.Lock()
 	defer config.Mtx.Unlock()
-	log := logger.GetInstance()
+	log := logger.Component(logger.ComponentPnl)
 
 	apiClient, err := bitunix.NewApiClient(config.ApiKey, config.SecretKey)
 	if err != nil {
 		log.Error("failed to create API client: %v", err)
 	}
 
+	wsLog := logger.Component(logger.ComponentWs)
 	ws, err := bitunix.NewPrivateWebsocket(ctx, config.ApiKey, config.SecretKey)
 	if err != nil {
-		log.Error("failed to create WebSocket client: %v", err)
+		wsLog.Error("failed to create WebSocket client: %v", err)
 	}
 	if err := ws.Connect(); err != nil {
-		log.Error("failed to connect to WebSocket client: %v", err)
+		wsLog.Error("failed to connect to WebSocket client: %v", err)
 	}
 	return apiClient, ws, err
 }
 
 type ProfitAndLoss struct {
 	realizedPnl     float64
+	perCoin         map[string]float64
+	positions       []history.ClosedPosition
+	timeline        []history.Point
+	giveBackHit     bool
+	currency        string
+	converter       *Converter
+	startingBalance float64
+	lossLimitHit    bool
+	history         *history.Store
+	calendar        tradingday.Calendar
+	menu            *statusMenu
 	mtx             sync.Mutex
 	config          *config.Config
 	mStatus         *systray.MenuItem
 	todayMorning    time.Time
 	tomorrowMorning time.Time
 	apiClient       bitunix.ApiClient
+	// ctx is the context of the tracking run; requests made from websocket
+	// callbacks derive from it.
+	ctx context.Context
+	// fetches numbers the fetches started and published the last one shown.
+	fetches   uint64
+	published uint64
 }
 
 func NewProfitAndLoss(initialProfitAndLoss float64, config *config.Config, mStatus *systray.MenuItem, apiClient bitunix.ApiClient, todayMorning, tomorrowMorning time.Time) *ProfitAndLoss {
@@ -218,69 +492,243 @@ func NewProfitAndLoss(initialProfitAndLoss float64, config *config.Config, mStat
 	return pnl
 }
 
-func IsFile(path string) bool {
-	fileInfo, err := os.Stat(path)
-	if err != nil {
-		return false
+// outputPath resolves the configured output location: a directory gets
+// pnl.txt inside it, anything else is used as file name.
+func outputPath(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "pnl.txt")
 	}
 
-	return fileInfo.Mode().IsRegular()
+	return path
 }
 
-func SavePnLToFile(pnl float64, filePath string) error {
+func SavePnLToFile(text string, filePath string) error {
 	if filePath == "" {
 		return fmt.Errorf("file path is empty")
 	}
-	log := logger.GetInstance()
+	log := logger.Component(logger.ComponentPnl)
 
-	if !IsFile(filePath) {
-		filePath = fmt.Sprintf("%s\\pnl.txt", filePath)
-		file, err := os.Create(filePath)
-		if err != nil {
-			log.Error("error creating pnl file:", err)
-		}
-		file.Close()
-	}
+	filePath = outputPath(filePath)
 
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		log.Error("error creating pnl file:", err)
+		log.Error("error creating pnl file: %v", err)
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	pnlString := fmt.Sprintf("%.2f$", pnl)
-
-	if err := os.WriteFile(filePath, []byte(pnlString), 0644); err != nil {
+	if err := os.WriteFile(filePath, []byte(text), 0644); err != nil {
 		return fmt.Errorf("failed to write PnL to file: %w", err)
 	}
 
-	log.Debug("saved PnL value %.2f to file: %s", pnl, filePath)
+	log.Debug("saved PnL %q to file: %s", text, filePath)
 	return nil
 }
 
-func (p *ProfitAndLoss) SubscribePosition(message *model.PositionChannelMessage) {
+// Flush writes the last known realized PnL to the output file, so the file
+// reflects the final state when tracking stops.
+func (p *ProfitAndLoss) Flush() {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
-	log := logger.GetInstance()
+	log := logger.Component(logger.ComponentPnl)
+
+	if p.config == nil || p.config.ProfitAndLossFile == "" {
+		return
+	}
+
+	if err := p.saveOutput(p.snapshot()); err != nil {
+		log.Warning("failed to flush PnL to file: %v", err)
+		return
+	}
+
+	log.Debug("flushed final realized pnl %.2f", p.realizedPnl)
+}
+
+func (p *ProfitAndLoss) SubscribePosition(message *model.PositionChannelMessage) {
+	log := logger.Component(logger.ComponentWs)
 
 	switch message.Data.Event {
 	case model.PositionEventClose, model.PositionEventUpdate, model.PositionEventOpen:
-		ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+		ctx, cancel := context.WithTimeout(p.ctx, fetchTimeout)
 		defer cancel()
 
-		realizedPnl, err := fetchBalance(ctx, p.todayMorning, p.tomorrowMorning, p.apiClient)
+		if total, ok := p.fetch(ctx); ok {
+			log.Debug("position close message received, realized pnl is now %.2f %s", total, p.currency)
+		}
+	}
+}
+
+// reconcile fetches today's positions from the exchange and publishes the
+// result.
+func (p *ProfitAndLoss) reconcile(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	if total, ok := p.fetch(ctx); ok {
+		logger.Component(logger.ComponentPnl).Info("realized pnl reconciled: %.2f %s", total, p.currency)
+	}
+}
+
+// fetch reloads today's positions and publishes the P&L. p.mtx is only held
+// once the exchange has answered, so a slow request does not block the
+// final flush. A result is dropped when a later fetch was published first.
+func (p *ProfitAndLoss) fetch(ctx context.Context) (float64, bool) {
+	p.mtx.Lock()
+	p.fetches++
+	fetch := p.fetches
+	p.mtx.Unlock()
+
+	positions, err := fetchBalance(ctx, p.todayMorning, p.tomorrowMorning, p.apiClient)
+	if err != nil {
+		logger.Component(logger.ComponentPnl).Error("failed to fetch pnl: %v", err)
+		return 0, false
+	}
+
+	amounts, total, err := p.convert(ctx, positions)
+	if err != nil {
+		logger.Component(logger.ComponentPnl).Error("failed to compute pnl: %v", err)
+		return 0, false
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	// Tracking may have stopped during the fetch; the history must not be
+	// written after the final flush.
+	if ctx.Err() != nil || fetch < p.published {
+		return 0, false
+	}
+	p.published = fetch
+
+	p.setPositions(positions, amounts, total)
+	p.publish()
+	return total, true
+}
+
+func (p *ProfitAndLoss) date() string {
+	return p.todayMorning.Format(history.DateFormat)
+}
+
+// snapshot collects the current figures. The caller must hold p.mtx.
+func (p *ProfitAndLoss) snapshot() Snapshot {
+	snapshot := Snapshot{
+		Date:            p.date(),
+		Currency:        p.currency,
+		Today:           p.realizedPnl,
+		StartingBalance: p.startingBalance,
+		PerCoin:         p.perCoin,
+		Trades:          len(p.positions),
+	}
+
+	if p.startingBalance > 0 {
+		snapshot.TodayPercent = p.realizedPnl / p.startingBalance * 100
+		snapshot.HasPercent = true
+	}
+
+	if p.history != nil {
+		snapshot.Rollups = computeRollups(p.history.Days(), p.calendar, p.todayMorning, p.realizedPnl)
+	}
+
+	return snapshot
+}
+
+func (p *ProfitAndLoss) saveOutput(snapshot Snapshot) error {
+	text, err := renderOutput(p.config.Template(), snapshot)
+	if err != nil {
+		logger.Component(logger.ComponentPnl).Warning("%v, using the default template", err)
+
+		text, err = renderOutput(config.DefaultOutputTemplate, snapshot)
 		if err != nil {
-			log.Error("failed to fetch pnl: %v", err)
+			return err
 		}
+	}
 
-		p.realizedPnl = realizedPnl
-		p.mStatus.SetTitle(fmt.Sprintf("Running - realized PnL %.2f$", p.realizedPnl))
-		log.Debug("position close message received, realized pnl is now %.2f", p.realizedPnl)
+	return SavePnLToFile(text, p.config.ProfitAndLossFile)
+}
 
-		if p.config != nil && p.config.ProfitAndLossFile != "" {
-			if err := SavePnLToFile(p.realizedPnl, p.config.ProfitAndLossFile); err != nil {
-				log.Warning("failed to save updated PnL to file: %v", err)
-			}
+// convert sums the realized P&L of positions per margin coin and in the
+// reporting currency. The currency and converter do not change while
+// tracking runs, so p.mtx need not be held.
+func (p *ProfitAndLoss) convert(ctx context.Context, positions []history.ClosedPosition) (map[string]float64, float64, error) {
+	amounts := perCoin(positions)
+	total, err := p.converter.Total(ctx, amounts, p.currency)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to convert pnl to %s: %w", p.currency, err)
+	}
+
+	return amounts, total, nil
+}
+
+// setPositions stores the positions closed today with their converted P&L.
+// The caller must hold p.mtx.
+func (p *ProfitAndLoss) setPositions(positions []history.ClosedPosition, amounts map[string]float64, total float64) {
+	p.positions = positions
+	p.perCoin = amounts
+	p.realizedPnl = total
+}
+
+// publish shows the current P&L in the tray and writes it to the output file
+// and the history. The caller must hold p.mtx.
+func (p *ProfitAndLoss) publish() {
+	log := logger.Component(logger.ComponentPnl)
+
+	title := fmt.Sprintf("Running - realized PnL %s", formatPnl(p.config, p.realizedPnl, p.startingBalance))
+	if p.checkLossLimit() {
+		title = "LOSS LIMIT - " + title
+	}
+	p.mStatus.SetTitle(title)
+	p.updateIcon()
+
+	p.record(time.Now())
+	p.checkGiveBack()
+
+	if p.history != nil {
+		if err := p.history.Update(p.date(), func(day *history.Day) {
+			day.RealizedPnl = p.realizedPnl
+			day.Currency = p.currency
+			day.PerCoin = p.perCoin
+			day.Positions = p.positions
+			day.Timeline = p.timeline
+		}); err != nil {
+			log.Warning("failed to record pnl history: %v", err)
 		}
 	}
+
+	snapshot := p.snapshot()
+	p.menu.update(p.config, snapshot.Rollups)
+	p.updateTitle(snapshot)
+	p.publishLive(snapshot)
+
+	if p.config != nil && p.config.ProfitAndLossFile != "" {
+		if err := p.saveOutput(snapshot); err != nil {
+			log.Warning("failed to save updated PnL to file: %v", err)
+		}
+	}
+}
+
+// checkLossLimit reports whether a configured daily loss limit is reached and
+// notifies once per trading day when it is.
+func (p *ProfitAndLoss) checkLossLimit() bool {
+	if p.config == nil {
+		return false
+	}
+	log := logger.Component(logger.ComponentPnl)
+
+	maxLoss, maxLossPercent := p.config.LossLimits()
+
+	reached := maxLoss > 0 && p.realizedPnl <= -maxLoss
+	if maxLossPercent > 0 && p.startingBalance > 0 && p.realizedPnl/p.startingBalance*100 <= -maxLossPercent {
+		reached = true
+	}
+
+	if reached && !p.lossLimitHit {
+		log.Warning("daily loss limit reached, realized pnl %.2f", p.realizedPnl)
+
+		err := beeep.Alert("TradingIQ PNL Tracker", fmt.Sprintf("Daily loss limit reached: %s", formatPnl(p.config, p.realizedPnl, p.startingBalance)), "assets/information.png")
+		if err != nil {
+			log.Warning("Could not notify about loss limit: %v", err)
+		}
+	}
+	p.lossLimitHit = reached
+
+	return reached
 }
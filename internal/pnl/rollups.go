@@ -0,0 +1,105 @@
+package pnl
+
+import (
+	"bytes"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/history"
+	"daily-profit-and-loss/internal/tradingday"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/getlantern/systray"
+)
+
+// Rollups are the realized P&L of the current week, month and year,
+// including today.
+type Rollups struct {
+	WeekToDate  float64
+	MonthToDate float64
+	YearToDate  float64
+}
+
+func computeRollups(days []history.Day, calendar tradingday.Calendar, now time.Time, today float64) Rollups {
+	todayDate := calendar.Date(now)
+	weekStart := calendar.WeekStartDate(now)
+	monthStart := calendar.MonthStartDate(now)
+	yearStart := calendar.YearStartDate(now)
+
+	rollups := Rollups{WeekToDate: today, MonthToDate: today, YearToDate: today}
+	for _, day := range days {
+		if day.Date >= todayDate {
+			continue
+		}
+		if day.Date >= weekStart {
+			rollups.WeekToDate += day.RealizedPnl
+		}
+		if day.Date >= monthStart {
+			rollups.MonthToDate += day.RealizedPnl
+		}
+		if day.Date >= yearStart {
+			rollups.YearToDate += day.RealizedPnl
+		}
+	}
+
+	return rollups
+}
+
+// Snapshot is the data available to the output template.
+type Snapshot struct {
+	Date            string
+	Currency        string
+	Today           float64
+	TodayPercent    float64
+	HasPercent      bool
+	StartingBalance float64
+	PerCoin         map[string]float64
+	// Trades is the number of positions closed today.
+	Trades int
+	Rollups
+}
+
+func renderOutput(tmpl string, snapshot Snapshot) (string, error) {
+	t, err := template.New("output").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid output template: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, snapshot); err != nil {
+		return "", fmt.Errorf("failed to render output template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// statusMenu holds the rollup items shown below the status entry of the tray.
+type statusMenu struct {
+	week  *systray.MenuItem
+	month *systray.MenuItem
+	year  *systray.MenuItem
+}
+
+func newStatusMenu(mStatus *systray.MenuItem) *statusMenu {
+	menu := &statusMenu{
+		week:  mStatus.AddSubMenuItem("Week to date: -", "Realized PnL of the current week"),
+		month: mStatus.AddSubMenuItem("Month to date: -", "Realized PnL of the current month"),
+		year:  mStatus.AddSubMenuItem("Year to date: -", "Realized PnL of the current year"),
+	}
+
+	menu.week.Disable()
+	menu.month.Disable()
+	menu.year.Disable()
+
+	return menu
+}
+
+func (m *statusMenu) update(cfg *config.Config, rollups Rollups) {
+	if m == nil {
+		return
+	}
+
+	m.week.SetTitle(fmt.Sprintf("Week to date: %s", formatPnl(cfg, rollups.WeekToDate, 0)))
+	m.month.SetTitle(fmt.Sprintf("Month to date: %s", formatPnl(cfg, rollups.MonthToDate, 0)))
+	m.year.SetTitle(fmt.Sprintf("Year to date: %s", formatPnl(cfg, rollups.YearToDate, 0)))
+}
@@ -0,0 +1,121 @@
+package pnl
+
+import (
+	app2 "daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/history"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/stats"
+	"daily-profit-and-loss/internal/ui"
+	"fmt"
+	"strings"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// LoadStatistics computes the statistics of the recorded trading days from
+// from to to. Empty dates default to the start of the year and today.
+func LoadStatistics(cfg *config.Config, from, to string) (stats.Stats, error) {
+	calendar := cfg.Calendar()
+	now := time.Now()
+
+	if from == "" {
+		from = calendar.YearStartDate(now)
+	}
+	if to == "" {
+		to = calendar.Date(now)
+	}
+
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse(history.DateFormat, date); err != nil {
+			return stats.Stats{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	store, err := history.Open(app2.GetHistoryPath())
+	if err != nil {
+		return stats.Stats{}, err
+	}
+
+	var days []history.Day
+	for _, day := range store.Days() {
+		if day.Date >= from && day.Date <= to {
+			days = append(days, day)
+		}
+	}
+
+	result := stats.Compute(days, cfg.Currency())
+	result.From, result.To = from, to
+
+	return result, nil
+}
+
+func RunStatisticsWindow(w *app.Window, cfg *config.Config, log *logger.Logger) error {
+	th := material.NewTheme()
+
+	var (
+		fromInput     widget.Editor
+		toInput       widget.Editor
+		refreshButton widget.Clickable
+		closeButton   widget.Clickable
+		list          = widget.List{List: layout.List{Axis: layout.Vertical}}
+	)
+
+	fromInput.SingleLine = true
+	toInput.SingleLine = true
+	fromInput.SetText(cfg.Calendar().YearStartDate(time.Now()))
+
+	var (
+		rows   []stats.Row
+		status string
+	)
+
+	refresh := func() {
+		result, err := LoadStatistics(cfg, strings.TrimSpace(fromInput.Text()), strings.TrimSpace(toInput.Text()))
+		if err != nil {
+			log.Error("failed to compute statistics: %v", err)
+			status = err.Error()
+			rows = nil
+			return
+		}
+
+		status = ""
+		rows = result.Rows(cfg.IsStreamerMode())
+		if result.Trades == 0 {
+			status = "No closed positions recorded in this period. Use Backfill History to fetch them."
+		}
+	}
+	refresh()
+
+	statisticsHandler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
+		th := theme.(*material.Theme)
+
+		ui.CloseButtonHandler(&closeButton, gtx, closeRequested)
+
+		if refreshButton.Clicked(gtx) {
+			refresh()
+		}
+
+		table := func(gtx layout.Context) layout.Dimensions {
+			return material.List(th, &list).Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+				return ui.InfoText(th, rows[i].Label+": "+rows[i].Value)(gtx)
+			})
+		}
+
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(ui.Title(th, "Statistics")),
+			layout.Rigid(ui.NewLabeledInput(th, "From:", "YYYY-MM-DD", &fromInput).Layout),
+			layout.Rigid(ui.NewLabeledInput(th, "To (optional):", "Today", &toInput).Layout),
+			layout.Rigid(ui.CenteredButton(th, &refreshButton, "Refresh")),
+			layout.Rigid(ui.StatusText(th, status)),
+			layout.Flexed(1, table),
+			layout.Rigid(ui.PaddedWidget(ui.CommonInsets.Button, ui.CenteredButton(th, &closeButton, "Close"))),
+		)
+	}
+
+	return ui.RunWindow(w, statisticsHandler, th)
+}
@@ -0,0 +1,88 @@
+package pnl
+
+import (
+	app2 "daily-profit-and-loss/internal/app"
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/history"
+	"daily-profit-and-loss/internal/logger"
+	"daily-profit-and-loss/internal/ui"
+	"fmt"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+const statusRefreshInterval = 2 * time.Second
+
+// RunStatusWindow shows today's P&L, its high-water mark and drawdown and a
+// sparkline of the intraday timeline. The figures are read from the history,
+// which the tracker updates on every change.
+func RunStatusWindow(w *app.Window, cfg *config.Config, log *logger.Logger) error {
+	th := material.NewTheme()
+
+	var closeButton widget.Clickable
+
+	store, err := history.Open(app2.GetHistoryPath())
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(statusRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.Invalidate()
+			}
+		}
+	}()
+
+	statusHandler := func(gtx layout.Context, theme interface{}, closeRequested chan bool) layout.Dimensions {
+		th := theme.(*material.Theme)
+
+		ui.CloseButtonHandler(&closeButton, gtx, closeRequested)
+
+		calendar := cfg.Calendar()
+		day, _ := store.Day(calendar.Date(time.Now()))
+		drawdown := analyzeTimeline(day.Timeline)
+
+		values := make([]float64, 0, len(day.Timeline)+1)
+		values = append(values, 0)
+		for _, point := range day.Timeline {
+			values = append(values, point.Pnl)
+		}
+
+		lastChange := "-"
+		if n := len(day.Timeline); n > 0 {
+			lastChange = day.Timeline[n-1].Time.In(calendar.Location).Format(time.TimeOnly)
+		}
+		highAt := "-"
+		if !drawdown.HighAt.IsZero() {
+			highAt = drawdown.HighAt.In(calendar.Location).Format(time.TimeOnly)
+		}
+
+		return ui.VerticalLayout(gtx,
+			ui.Title(th, "Today"),
+			ui.InfoText(th, fmt.Sprintf("Realized P&L: %s", formatPnl(cfg, day.RealizedPnl, day.StartingBalance))),
+			ui.InfoText(th, fmt.Sprintf("High: %s at %s", formatPnl(cfg, drawdown.High, day.StartingBalance), highAt)),
+			ui.InfoText(th, fmt.Sprintf("Below high: %s", formatPnl(cfg, drawdown.Current, day.StartingBalance))),
+			ui.InfoText(th, fmt.Sprintf("Max drawdown: %s", formatPnl(cfg, drawdown.Max, day.StartingBalance))),
+			ui.InfoText(th, fmt.Sprintf("Last change: %s (%d changes)", lastChange, len(day.Timeline))),
+			ui.PaddedWidget(ui.CommonInsets.Field, ui.Sparkline(values, unit.Dp(80))),
+			ui.CenteredButton(th, &closeButton, "Close"),
+		)
+	}
+
+	return ui.RunWindow(w, statusHandler, th)
+}
@@ -0,0 +1,75 @@
+package pnl
+
+import (
+	"daily-profit-and-loss/internal/history"
+	"daily-profit-and-loss/internal/logger"
+	"fmt"
+	"time"
+
+	"github.com/gen2brain/beeep"
+)
+
+// Drawdown summarises the intraday timeline. The day starts flat, so the
+// high-water mark is never below zero.
+type Drawdown struct {
+	High   float64
+	HighAt time.Time
+	// Max is the largest drop from a previous high during the day, Current
+	// the distance of the latest P&L to the high.
+	Max     float64
+	Current float64
+}
+
+func analyzeTimeline(points []history.Point) Drawdown {
+	var drawdown Drawdown
+
+	for _, point := range points {
+		if point.Pnl > drawdown.High {
+			drawdown.High = point.Pnl
+			drawdown.HighAt = point.Time
+		}
+
+		drawdown.Current = drawdown.High - point.Pnl
+		drawdown.Max = max(drawdown.Max, drawdown.Current)
+	}
+
+	return drawdown
+}
+
+// record appends the realized P&L to the timeline when it changed. The caller
+// must hold p.mtx.
+func (p *ProfitAndLoss) record(now time.Time) {
+	if n := len(p.timeline); n > 0 && p.timeline[n-1].Pnl == p.realizedPnl {
+		return
+	}
+
+	p.timeline = append(p.timeline, history.Point{Time: now, Pnl: p.realizedPnl})
+}
+
+// checkGiveBack notifies when the P&L fell the configured amount below the
+// day's high. It notifies again only after the P&L recovered in between.
+func (p *ProfitAndLoss) checkGiveBack() {
+	if p.config == nil {
+		return
+	}
+	log := logger.Component(logger.ComponentPnl)
+
+	amount := p.config.GiveBack()
+	if amount <= 0 {
+		return
+	}
+
+	drawdown := analyzeTimeline(p.timeline)
+	reached := drawdown.Current >= amount
+
+	if reached && !p.giveBackHit {
+		log.Warning("gave back %.2f from the day's high of %.2f", drawdown.Current, drawdown.High)
+
+		message := fmt.Sprintf("Gave back %s from today's high of %s",
+			formatPnl(p.config, drawdown.Current, p.startingBalance), formatPnl(p.config, drawdown.High, p.startingBalance))
+		if err := beeep.Alert("TradingIQ PNL Tracker", message, "assets/information.png"); err != nil {
+			log.Warning("Could not notify about give-back: %v", err)
+		}
+	}
+	p.giveBackHit = reached
+}
@@ -0,0 +1,105 @@
+package pnl
+
+import (
+	"daily-profit-and-loss/internal/config"
+	"daily-profit-and-loss/internal/logger"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getlantern/systray"
+)
+
+const (
+	appTitle = "TradingIQ's Daily Crypto Profit And Loss Tracker"
+	// titleInterval is the minimum time between two updates of the tray
+	// title, so a burst of position updates does not make it flicker.
+	titleInterval = time.Second
+)
+
+// titleUpdater throttles updates of the tray title and tooltip. The last
+// values set within an interval are applied when it ends.
+type titleUpdater struct {
+	mtx     sync.Mutex
+	title   string
+	tooltip string
+	last    time.Time
+	timer   *time.Timer
+}
+
+var trayTitle = &titleUpdater{}
+
+func (u *titleUpdater) set(title, tooltip string) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	u.title, u.tooltip = title, tooltip
+	if u.timer != nil {
+		return
+	}
+
+	wait := titleInterval - time.Since(u.last)
+	if wait <= 0 {
+		u.apply()
+		return
+	}
+
+	u.timer = time.AfterFunc(wait, func() {
+		u.mtx.Lock()
+		defer u.mtx.Unlock()
+
+		u.timer = nil
+		u.apply()
+	})
+}
+
+// apply shows the pending values. The caller must hold u.mtx.
+func (u *titleUpdater) apply() {
+	systray.SetTitle(u.title)
+	systray.SetTooltip(u.tooltip)
+	u.last = time.Now()
+}
+
+// updateTitle renders the tray title template and the tooltip breakdown. The
+// caller must hold p.mtx.
+func (p *ProfitAndLoss) updateTitle(snapshot Snapshot) {
+	if p.config == nil {
+		return
+	}
+
+	title, err := renderOutput(p.config.TrayTitleTemplate(), snapshot)
+	if err != nil {
+		logger.Component(logger.ComponentPnl).Warning("%v, using the default tray title", err)
+
+		title, _ = renderOutput(config.DefaultTitleTemplate, snapshot)
+	}
+
+	trayTitle.set(title, p.tooltip(snapshot))
+}
+
+// tooltip lists the figures of the day, one per line.
+func (p *ProfitAndLoss) tooltip(snapshot Snapshot) string {
+	lines := []string{
+		fmt.Sprintf("Today: %s", formatPnl(p.config, snapshot.Today, snapshot.StartingBalance)),
+		fmt.Sprintf("Trades: %d", snapshot.Trades),
+	}
+
+	if !p.config.IsStreamerMode() && len(snapshot.PerCoin) > 1 {
+		lines = append(lines, fmt.Sprintf("Per coin: %s", formatAmounts(snapshot.PerCoin)))
+	}
+
+	drawdown := analyzeTimeline(p.timeline)
+	lines = append(lines,
+		fmt.Sprintf("High: %s", formatPnl(p.config, drawdown.High, snapshot.StartingBalance)),
+		fmt.Sprintf("Week: %s", formatPnl(p.config, snapshot.WeekToDate, 0)),
+		fmt.Sprintf("Month: %s", formatPnl(p.config, snapshot.MonthToDate, 0)),
+		fmt.Sprintf("Year: %s", formatPnl(p.config, snapshot.YearToDate, 0)),
+	)
+
+	if progress := p.lossProgress(); progress > 0 {
+		lines = append(lines, fmt.Sprintf("Loss limit: %.0f%% used", progress*100))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	service = "daily-pnl"
+
+	PassphraseEnv = "DAILY_PNL_VAULT_PASSPHRASE"
+)
+
+var (
+	ErrNotFound = errors.New("secret not found")
+	ErrNoStore  = errors.New("no secure credential store available")
+)
+
+type Store interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// Open returns the OS keychain when it is usable and falls back to the
+// passphrase-encrypted vault when a passphrase is provided.
+func Open(vaultPath string) (Store, error) {
+	keyringErr := probeKeyring()
+	if keyringErr == nil {
+		return &keyringStore{}, nil
+	}
+
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("%w: keyring unavailable (%v) and %s is not set", ErrNoStore, keyringErr, PassphraseEnv)
+	}
+
+	return NewVault(vaultPath, passphrase), nil
+}
+
+func probeKeyring() error {
+	_, err := keyring.Get(service, "probe")
+	if err == nil || errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+type keyringStore struct{}
+
+func (k *keyringStore) Name() string {
+	return "OS keyring"
+}
+
+func (k *keyringStore) Get(key string) (string, error) {
+	value, err := keyring.Get(service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+
+	return value, err
+}
+
+func (k *keyringStore) Set(key, value string) error {
+	return keyring.Set(service, key, value)
+}
+
+func (k *keyringStore) Delete(key string) error {
+	err := keyring.Delete(service, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
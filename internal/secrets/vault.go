@@ -0,0 +1,184 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	vaultIterations = 600000
+	vaultKeyLength  = 32
+	vaultSaltLength = 16
+)
+
+type vaultFile struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Vault keeps secrets in a local file encrypted with AES-256-GCM, using a key
+// derived from a passphrase. The key is derived once and the salt kept for
+// later writes, since the derivation is deliberately slow.
+type Vault struct {
+	path       string
+	passphrase string
+	salt       []byte
+	key        []byte
+	mtx        sync.Mutex
+}
+
+func NewVault(path, passphrase string) *Vault {
+	return &Vault{
+		path:       path,
+		passphrase: passphrase,
+	}
+}
+
+func (v *Vault) Name() string {
+	return "encrypted vault"
+}
+
+func (v *Vault) Get(key string) (string, error) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	values, err := v.read()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+func (v *Vault) Set(key, value string) error {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	values, err := v.read()
+	if err != nil {
+		return err
+	}
+
+	values[key] = value
+
+	return v.write(values)
+}
+
+func (v *Vault) Delete(key string) error {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	values, err := v.read()
+	if err != nil {
+		return err
+	}
+
+	delete(values, key)
+
+	return v.write(values)
+}
+
+func (v *Vault) read() (map[string]string, error) {
+	values := make(map[string]string)
+
+	data, err := os.ReadFile(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse vault: %w", err)
+	}
+
+	gcm, err := v.cipher(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt vault, wrong passphrase? %w", err)
+	}
+
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse vault contents: %w", err)
+	}
+
+	return values, nil
+}
+
+func (v *Vault) write(values map[string]string) error {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault contents: %w", err)
+	}
+
+	file := vaultFile{Salt: v.salt}
+	if file.Salt == nil {
+		file.Salt = make([]byte, vaultSaltLength)
+		if _, err := rand.Read(file.Salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+
+	gcm, err := v.cipher(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	return os.WriteFile(v.path, data, 0600)
+}
+
+// cipher returns the cipher for salt, deriving the key only when the salt
+// differs from the one used before. The caller must hold v.mtx.
+func (v *Vault) cipher(salt []byte) (cipher.AEAD, error) {
+	if v.key == nil || !bytes.Equal(salt, v.salt) {
+		key, err := pbkdf2.Key(sha256.New, v.passphrase, salt, vaultIterations, vaultKeyLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive vault key: %w", err)
+		}
+		v.salt, v.key = salt, key
+	}
+
+	block, err := aes.NewCipher(v.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
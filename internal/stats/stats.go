@@ -0,0 +1,297 @@
+package stats
+
+import (
+	"daily-profit-and-loss/internal/history"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// tradingDaysPerYear annualizes the Sharpe and Sortino ratios. Crypto futures
+// trade every day of the year.
+const tradingDaysPerYear = 365
+
+// Summary describes a set of closed positions. Amounts are in the reporting
+// currency, WinRate is in percent.
+type Summary struct {
+	Trades               int
+	Wins                 int
+	Losses               int
+	WinRate              float64
+	GrossProfit          float64
+	GrossLoss            float64
+	NetPnl               float64
+	ProfitFactor         float64
+	AverageWin           float64
+	AverageLoss          float64
+	Expectancy           float64
+	MaxConsecutiveLosses int
+}
+
+// Stats is the trading performance over a range of trading days.
+type Stats struct {
+	From     string
+	To       string
+	Currency string
+	Days     int
+	// SkippedDays were recorded in another reporting currency and are not
+	// included.
+	SkippedDays int
+	Summary
+
+	// MaxDrawdown is the largest drop of the cumulative daily P&L from its
+	// previous peak.
+	MaxDrawdown float64
+	// Sharpe and Sortino are annualized from the daily returns of the days
+	// with a known starting balance. They are zero with fewer than two such
+	// days.
+	Sharpe  float64
+	Sortino float64
+
+	BySymbol map[string]Summary
+	BySide   map[string]Summary
+}
+
+type accumulator struct {
+	Summary
+	consecutiveLosses int
+}
+
+func (a *accumulator) add(pnl float64) {
+	a.Trades++
+	a.NetPnl += pnl
+
+	switch {
+	case pnl > 0:
+		a.Wins++
+		a.GrossProfit += pnl
+		a.consecutiveLosses = 0
+	case pnl < 0:
+		a.Losses++
+		a.GrossLoss -= pnl
+		a.consecutiveLosses++
+		a.MaxConsecutiveLosses = max(a.MaxConsecutiveLosses, a.consecutiveLosses)
+	}
+}
+
+func (a *accumulator) summary() Summary {
+	s := a.Summary
+	if s.Trades == 0 {
+		return s
+	}
+
+	s.WinRate = float64(s.Wins) / float64(s.Trades) * 100
+	if s.Wins > 0 {
+		s.AverageWin = s.GrossProfit / float64(s.Wins)
+	}
+	if s.Losses > 0 {
+		s.AverageLoss = s.GrossLoss / float64(s.Losses)
+	}
+	switch {
+	case s.GrossLoss > 0:
+		s.ProfitFactor = s.GrossProfit / s.GrossLoss
+	case s.GrossProfit > 0:
+		s.ProfitFactor = math.Inf(1)
+	}
+	s.Expectancy = s.NetPnl / float64(s.Trades)
+
+	return s
+}
+
+// Compute returns the statistics of the given days, which must be sorted by
+// date. Days recorded in another reporting currency are skipped; days without
+// a currency predate it being recorded and are included.
+func Compute(days []history.Day, currency string) Stats {
+	stats := Stats{
+		Currency: currency,
+		BySymbol: make(map[string]Summary),
+		BySide:   make(map[string]Summary),
+	}
+	if len(days) > 0 {
+		stats.From, stats.To = days[0].Date, days[len(days)-1].Date
+	}
+
+	var (
+		all      accumulator
+		bySymbol = make(map[string]*accumulator)
+		bySide   = make(map[string]*accumulator)
+		returns  []float64
+		equity   float64
+		peak     float64
+	)
+
+	for _, day := range days {
+		if day.Currency != "" && day.Currency != currency {
+			stats.SkippedDays++
+			continue
+		}
+		stats.Days++
+
+		for _, position := range day.Positions {
+			pnl := convert(day, position)
+
+			all.add(pnl)
+			group(bySymbol, position.Symbol).add(pnl)
+			group(bySide, position.Side).add(pnl)
+		}
+
+		equity += day.RealizedPnl
+		peak = max(peak, equity)
+		stats.MaxDrawdown = max(stats.MaxDrawdown, peak-equity)
+
+		if percent, ok := day.ReturnPercent(); ok {
+			returns = append(returns, percent/100)
+		}
+	}
+
+	stats.Summary = all.summary()
+	for symbol, acc := range bySymbol {
+		stats.BySymbol[symbol] = acc.summary()
+	}
+	for side, acc := range bySide {
+		stats.BySide[side] = acc.summary()
+	}
+	stats.Sharpe, stats.Sortino = ratios(returns)
+
+	return stats
+}
+
+func group(groups map[string]*accumulator, key string) *accumulator {
+	if groups[key] == nil {
+		groups[key] = &accumulator{}
+	}
+
+	return groups[key]
+}
+
+// convert expresses a position's P&L in the day's reporting currency. The
+// rate is derived from the day's totals when the day has a single margin coin;
+// otherwise margin coins are assumed to be stablecoins worth one unit.
+func convert(day history.Day, position history.ClosedPosition) float64 {
+	if position.MarginCoin == day.Currency || len(day.PerCoin) != 1 {
+		return position.RealizedPnl
+	}
+
+	coinTotal := day.PerCoin[position.MarginCoin]
+	if coinTotal == 0 {
+		return position.RealizedPnl
+	}
+
+	return position.RealizedPnl * day.RealizedPnl / coinTotal
+}
+
+func ratios(returns []float64) (sharpe, sortino float64) {
+	if len(returns) < 2 {
+		return 0, 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance, downside float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	deviation := math.Sqrt(variance / float64(len(returns)-1))
+	downsideDeviation := math.Sqrt(downside / float64(len(returns)))
+
+	annualize := math.Sqrt(tradingDaysPerYear)
+	if deviation > 0 {
+		sharpe = mean / deviation * annualize
+	}
+	if downsideDeviation > 0 {
+		sortino = mean / downsideDeviation * annualize
+	}
+
+	return sharpe, sortino
+}
+
+// Row is a labelled value of the report.
+type Row struct {
+	Label string
+	Value string
+}
+
+// hiddenAmount replaces amounts when they must not be shown.
+const hiddenAmount = "hidden"
+
+// Rows returns the statistics as labelled, formatted values. With
+// hideAmounts, as in streamer mode, only counts, rates and ratios are shown.
+func (s Stats) Rows(hideAmounts bool) []Row {
+	amount := func(value float64) string {
+		if hideAmounts {
+			return hiddenAmount
+		}
+		return fmt.Sprintf("%.2f %s", value, s.Currency)
+	}
+
+	rows := []Row{
+		{"Period", fmt.Sprintf("%s to %s (%d days)", s.From, s.To, s.Days)},
+		{"Trades", fmt.Sprintf("%d (%d wins, %d losses)", s.Trades, s.Wins, s.Losses)},
+		{"Win rate", fmt.Sprintf("%.1f%%", s.WinRate)},
+		{"Net P&L", amount(s.NetPnl)},
+		{"Profit factor", formatRatio(s.ProfitFactor)},
+		{"Average win", amount(s.AverageWin)},
+		{"Average loss", amount(s.AverageLoss)},
+		{"Expectancy", amount(s.Expectancy)},
+		{"Max consecutive losses", fmt.Sprint(s.MaxConsecutiveLosses)},
+		{"Max drawdown", amount(s.MaxDrawdown)},
+		{"Sharpe ratio", fmt.Sprintf("%.2f", s.Sharpe)},
+		{"Sortino ratio", fmt.Sprintf("%.2f", s.Sortino)},
+	}
+
+	if s.SkippedDays > 0 {
+		rows = append(rows, Row{"Skipped days", fmt.Sprintf("%d in another currency than %s", s.SkippedDays, s.Currency)})
+	}
+
+	rows = append(rows, groupRows("Side", s.BySide, amount)...)
+	rows = append(rows, groupRows("Symbol", s.BySymbol, amount)...)
+
+	return rows
+}
+
+func groupRows(kind string, groups map[string]Summary, amount func(float64) string) []Row {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rows := make([]Row, 0, len(keys))
+	for _, key := range keys {
+		summary := groups[key]
+		rows = append(rows, Row{
+			Label: fmt.Sprintf("%s %s", kind, key),
+			Value: fmt.Sprintf("%d trades, %.1f%% win rate, %s, profit factor %s",
+				summary.Trades, summary.WinRate, amount(summary.NetPnl), formatRatio(summary.ProfitFactor)),
+		})
+	}
+
+	return rows
+}
+
+func formatRatio(value float64) string {
+	if math.IsInf(value, 1) {
+		return "∞"
+	}
+
+	return fmt.Sprintf("%.2f", value)
+}
+
+// WriteReport prints the statistics as an aligned table.
+func (s Stats) WriteReport(w io.Writer, hideAmounts bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range s.Rows(hideAmounts) {
+		fmt.Fprintf(tw, "%s\t%s\n", row.Label, row.Value)
+	}
+
+	return tw.Flush()
+}
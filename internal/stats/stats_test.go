@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"daily-profit-and-loss/internal/history"
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < epsilon || (math.IsInf(a, 1) && math.IsInf(b, 1))
+}
+
+func position(symbol, side string, pnl float64) history.ClosedPosition {
+	return history.ClosedPosition{Symbol: symbol, Side: side, MarginCoin: "USDT", RealizedPnl: pnl}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		pnls []float64
+		want Summary
+	}{
+		{"no trades", nil, Summary{}},
+		{"wins only", []float64{10, 30}, Summary{
+			Trades: 2, Wins: 2, WinRate: 100, GrossProfit: 40, NetPnl: 40,
+			ProfitFactor: math.Inf(1), AverageWin: 20, Expectancy: 20,
+		}},
+		{"losses only", []float64{-10, -30}, Summary{
+			Trades: 2, Losses: 2, GrossLoss: 40, NetPnl: -40,
+			AverageLoss: 20, Expectancy: -20, MaxConsecutiveLosses: 2,
+		}},
+		{"mixed", []float64{-5, 20, -10, -5, 0}, Summary{
+			Trades: 5, Wins: 1, Losses: 3, WinRate: 20, GrossProfit: 20, GrossLoss: 20, NetPnl: 0,
+			ProfitFactor: 1, AverageWin: 20, AverageLoss: 20.0 / 3, MaxConsecutiveLosses: 2,
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var acc accumulator
+			for _, pnl := range test.pnls {
+				acc.add(pnl)
+			}
+			got := acc.summary()
+
+			if got.Trades != test.want.Trades || got.Wins != test.want.Wins || got.Losses != test.want.Losses ||
+				got.MaxConsecutiveLosses != test.want.MaxConsecutiveLosses {
+				t.Errorf("counts %+v, want %+v", got, test.want)
+			}
+			for _, value := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"win rate", got.WinRate, test.want.WinRate},
+				{"gross profit", got.GrossProfit, test.want.GrossProfit},
+				{"gross loss", got.GrossLoss, test.want.GrossLoss},
+				{"net pnl", got.NetPnl, test.want.NetPnl},
+				{"profit factor", got.ProfitFactor, test.want.ProfitFactor},
+				{"average win", got.AverageWin, test.want.AverageWin},
+				{"average loss", got.AverageLoss, test.want.AverageLoss},
+				{"expectancy", got.Expectancy, test.want.Expectancy},
+			} {
+				if !closeTo(value.got, value.want) {
+					t.Errorf("%s %v, want %v", value.name, value.got, value.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRatios(t *testing.T) {
+	tests := []struct {
+		name            string
+		returns         []float64
+		sharpe, sortino float64
+	}{
+		{"no returns", nil, 0, 0},
+		{"one return", []float64{0.05}, 0, 0},
+		{"no deviation", []float64{0.01, 0.01}, 0, 0},
+		{"gains only", []float64{0.01, 0.03}, math.Sqrt(730), 0},
+		{"gain and loss", []float64{-0.01, 0.03}, math.Sqrt(730) / 4, math.Sqrt(730)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sharpe, sortino := ratios(test.returns)
+			if !closeTo(sharpe, test.sharpe) || !closeTo(sortino, test.sortino) {
+				t.Errorf("got sharpe %v and sortino %v, want %v and %v", sharpe, sortino, test.sharpe, test.sortino)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		day      history.Day
+		position history.ClosedPosition
+		want     float64
+	}{
+		{"margin coin is the currency",
+			history.Day{Currency: "USDT", RealizedPnl: 10, PerCoin: map[string]float64{"USDT": 10}},
+			position("BTCUSDT", "LONG", 10), 10},
+		{"rate of the day",
+			history.Day{Currency: "EUR", RealizedPnl: 90, PerCoin: map[string]float64{"USDT": 100}},
+			position("BTCUSDT", "LONG", 10), 9},
+		{"several margin coins",
+			history.Day{Currency: "EUR", RealizedPnl: 90, PerCoin: map[string]float64{"USDT": 50, "USDC": 50}},
+			position("BTCUSDT", "LONG", 10), 10},
+		{"zero coin total",
+			history.Day{Currency: "EUR", RealizedPnl: 0, PerCoin: map[string]float64{"USDT": 0}},
+			position("BTCUSDT", "LONG", 10), 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := convert(test.day, test.position); !closeTo(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	days := []history.Day{
+		{Date: "2026-01-01", Currency: "USDT", RealizedPnl: 10, StartingBalance: 1000,
+			PerCoin: map[string]float64{"USDT": 10}, Positions: []history.ClosedPosition{
+				position("BTCUSDT", "LONG", 15), position("ETHUSDT", "SHORT", -5),
+			}},
+		{Date: "2026-01-02", Currency: "EUR", RealizedPnl: -100, StartingBalance: 900,
+			PerCoin: map[string]float64{"USDT": -110}, Positions: []history.ClosedPosition{
+				position("BTCUSDT", "LONG", -110),
+			}},
+		{Date: "2026-01-03", RealizedPnl: -20,
+			PerCoin: map[string]float64{"USDT": -20}, Positions: []history.ClosedPosition{
+				position("BTCUSDT", "LONG", -20),
+			}},
+		{Date: "2026-01-04", Currency: "USDT", RealizedPnl: 5, StartingBalance: 1000,
+			PerCoin: map[string]float64{"USDT": 5}, Positions: []history.ClosedPosition{
+				position("ETHUSDT", "LONG", 5),
+			}},
+	}
+
+	stats := Compute(days, "USDT")
+
+	if stats.From != "2026-01-01" || stats.To != "2026-01-04" {
+		t.Errorf("period %s to %s", stats.From, stats.To)
+	}
+	if stats.Days != 3 || stats.SkippedDays != 1 {
+		t.Errorf("%d days and %d skipped, want 3 and 1", stats.Days, stats.SkippedDays)
+	}
+	if stats.Trades != 4 || stats.Wins != 2 || stats.Losses != 2 || stats.MaxConsecutiveLosses != 2 {
+		t.Errorf("summary %+v", stats.Summary)
+	}
+	if !closeTo(stats.NetPnl, -5) || !closeTo(stats.ProfitFactor, 0.8) {
+		t.Errorf("net pnl %v and profit factor %v, want -5 and 0.8", stats.NetPnl, stats.ProfitFactor)
+	}
+	if !closeTo(stats.MaxDrawdown, 20) {
+		t.Errorf("max drawdown %v, want 20", stats.MaxDrawdown)
+	}
+
+	wantSharpe, wantSortino := ratios([]float64{0.01, 0.005})
+	if !closeTo(stats.Sharpe, wantSharpe) || !closeTo(stats.Sortino, wantSortino) {
+		t.Errorf("sharpe %v and sortino %v, want %v and %v", stats.Sharpe, stats.Sortino, wantSharpe, wantSortino)
+	}
+
+	if btc := stats.BySymbol["BTCUSDT"]; btc.Trades != 2 || !closeTo(btc.NetPnl, -5) {
+		t.Errorf("BTCUSDT %+v, want 2 trades and -5", btc)
+	}
+	if long := stats.BySide["LONG"]; long.Trades != 3 || !closeTo(long.NetPnl, 0) {
+		t.Errorf("LONG %+v, want 3 trades and 0", long)
+	}
+}
@@ -0,0 +1,129 @@
+package tradingday
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata"
+)
+
+const DateFormat = "2006-01-02"
+
+// Calendar defines when a trading day starts. A trading day is labelled with
+// the date it starts on.
+type Calendar struct {
+	Location  *time.Location
+	StartHour int
+	WeekStart time.Weekday
+}
+
+func New(timezone string, startHour int, weekStart string) (Calendar, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Calendar{}, fmt.Errorf("unknown timezone %q: %w", timezone, err)
+	}
+
+	if startHour < 0 || startHour > 23 {
+		return Calendar{}, fmt.Errorf("start hour %d is not between 0 and 23", startHour)
+	}
+
+	weekday, err := ParseWeekday(weekStart)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	return Calendar{
+		Location:  location,
+		StartHour: startHour,
+		WeekStart: weekday,
+	}, nil
+}
+
+func ParseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("unknown weekday %q", name)
+}
+
+// DayStart returns the start of the trading day containing t.
+func (c Calendar) DayStart(t time.Time) time.Time {
+	t = t.In(c.Location)
+
+	start := time.Date(t.Year(), t.Month(), t.Day(), c.StartHour, 0, 0, 0, c.Location)
+	if t.Before(start) {
+		start = time.Date(t.Year(), t.Month(), t.Day()-1, c.StartHour, 0, 0, 0, c.Location)
+	}
+
+	return start
+}
+
+// NextDayStart returns the start of the trading day following the one
+// containing t.
+func (c Calendar) NextDayStart(t time.Time) time.Time {
+	start := c.DayStart(t)
+	return time.Date(start.Year(), start.Month(), start.Day()+1, c.StartHour, 0, 0, 0, c.Location)
+}
+
+// Date returns the label of the trading day containing t.
+func (c Calendar) Date(t time.Time) string {
+	return c.DayStart(t).Format(DateFormat)
+}
+
+// Bounds returns the first and last instant of the trading day labelled date.
+func (c Calendar) Bounds(date string) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation(DateFormat, date, c.Location)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), c.StartHour, 0, 0, 0, c.Location)
+	end := c.NextDayStart(start).Add(-time.Second)
+
+	return start, end, nil
+}
+
+// WeekStartDate returns the label of the first trading day of the week
+// containing t.
+func (c Calendar) WeekStartDate(t time.Time) string {
+	start := c.DayStart(t)
+	offset := (int(start.Weekday()) - int(c.WeekStart) + 7) % 7
+
+	return start.AddDate(0, 0, -offset).Format(DateFormat)
+}
+
+// MonthStartDate returns the label of the first trading day of the month
+// containing t.
+func (c Calendar) MonthStartDate(t time.Time) string {
+	start := c.DayStart(t)
+	return time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, c.Location).Format(DateFormat)
+}
+
+// YearStartDate returns the label of the first trading day of the year
+// containing t.
+func (c Calendar) YearStartDate(t time.Time) string {
+	start := c.DayStart(t)
+	return time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, c.Location).Format(DateFormat)
+}
+
+// Dates lists the labels of all trading days from first to last, inclusive.
+func Dates(first, last string) []string {
+	from, err := time.Parse(DateFormat, first)
+	if err != nil {
+		return nil
+	}
+	to, err := time.Parse(DateFormat, last)
+	if err != nil {
+		return nil
+	}
+
+	var dates []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day.Format(DateFormat))
+	}
+
+	return dates
+}
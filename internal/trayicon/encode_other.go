@@ -0,0 +1,18 @@
+//go:build !windows
+
+package trayicon
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+)
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
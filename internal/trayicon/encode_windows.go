@@ -0,0 +1,38 @@
+package trayicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+)
+
+// encode wraps the icon as PNG in a single-image ICO file, which the
+// Windows tray expects.
+func encode(img image.Image) ([]byte, error) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		return nil, err
+	}
+
+	const headerSize = 6 + 16
+	bounds := img.Bounds()
+
+	var buf bytes.Buffer
+	header := []any{
+		// ICONDIR: reserved, type icon, one image
+		uint16(0), uint16(1), uint16(1),
+		// ICONDIRENTRY: width, height, palette, reserved, planes, bits per
+		// pixel, data size and offset
+		uint8(bounds.Dx()), uint8(bounds.Dy()), uint8(0), uint8(0),
+		uint16(1), uint16(32), uint32(data.Len()), uint32(headerSize),
+	}
+	for _, field := range header {
+		if err := binary.Write(&buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+	buf.Write(data.Bytes())
+
+	return buf.Bytes(), nil
+}
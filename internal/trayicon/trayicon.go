@@ -0,0 +1,197 @@
+// Package trayicon renders the tray icon from the current P&L.
+package trayicon
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Styles of the tray icon. StyleStatic keeps the application icon.
+const (
+	StyleStatic = "static"
+	StyleTint   = "tint"
+	StyleNumber = "number"
+	StyleRing   = "ring"
+)
+
+// ValidStyle reports whether style is one of the known styles.
+func ValidStyle(style string) bool {
+	switch style {
+	case StyleStatic, StyleTint, StyleNumber, StyleRing:
+		return true
+	}
+
+	return false
+}
+
+// State is the state of the tracker.
+type State int
+
+const (
+	Inactive State = iota
+	Running
+	Paused
+	Failed
+)
+
+const size = 32
+
+var (
+	gainColor     = color.RGBA{R: 0x2e, G: 0x9e, B: 0x44, A: 0xff}
+	lossColor     = color.RGBA{R: 0xd0, G: 0x2f, B: 0x2f, A: 0xff}
+	flatColor     = color.RGBA{R: 0x60, G: 0x7d, B: 0x8b, A: 0xff}
+	inactiveColor = color.RGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff}
+	failedColor   = color.RGBA{R: 0xef, G: 0x8c, B: 0x00, A: 0xff}
+	trackColor    = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	textColor     = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// Options describe the icon to render.
+type Options struct {
+	Style string
+	State State
+	Pnl   float64
+	// Label is drawn by StyleNumber, for example the rounded P&L.
+	Label string
+	// LossProgress is the share of the daily loss limit used, from 0 to 1.
+	// StyleRing draws it around the icon.
+	LossProgress float64
+}
+
+// Render draws the icon and encodes it for the tray of the current platform:
+// ICO on Windows, PNG elsewhere.
+func Render(opts Options) ([]byte, error) {
+	return encode(Draw(opts))
+}
+
+// Draw returns the icon bitmap.
+func Draw(opts Options) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	tint := tintColor(opts)
+	center := float64(size) / 2
+
+	switch opts.Style {
+	case StyleRing:
+		fillCircle(img, center, center-5, tint)
+		drawArc(img, center, center-3, center, 1, trackColor)
+		drawArc(img, center, center-3, center, math.Min(math.Max(opts.LossProgress, 0), 1), lossColor)
+	default:
+		fillCircle(img, center, center, tint)
+	}
+
+	if opts.Style == StyleNumber && opts.Label != "" {
+		drawLabel(img, opts.Label)
+	}
+
+	return img
+}
+
+func tintColor(opts Options) color.RGBA {
+	switch {
+	case opts.State == Failed:
+		return failedColor
+	case opts.State != Running:
+		return inactiveColor
+	case opts.Pnl > 0:
+		return gainColor
+	case opts.Pnl < 0:
+		return lossColor
+	default:
+		return flatColor
+	}
+}
+
+// fillCircle draws an anti-aliased disc.
+func fillCircle(img *image.RGBA, center, radius float64, c color.RGBA) {
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			d := math.Hypot(float64(x)+0.5-center, float64(y)+0.5-center)
+			blend(img, x, y, c, radius-d+0.5)
+		}
+	}
+}
+
+// drawArc draws the share progress of a ring between inner and outer,
+// clockwise from the top.
+func drawArc(img *image.RGBA, center, inner, outer, progress float64, c color.RGBA) {
+	if progress <= 0 {
+		return
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-center, float64(y)+0.5-center
+
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			if angle > progress*2*math.Pi {
+				continue
+			}
+
+			d := math.Hypot(dx, dy)
+			blend(img, x, y, c, math.Min(outer-d+0.5, d-inner+0.5))
+		}
+	}
+}
+
+func blend(img *image.RGBA, x, y int, c color.RGBA, coverage float64) {
+	coverage = math.Min(math.Max(coverage, 0), 1)
+	if coverage == 0 {
+		return
+	}
+
+	dst := img.RGBAAt(x, y)
+	mix := func(src, dst uint8) uint8 {
+		return uint8(float64(src)*coverage + float64(dst)*(1-coverage))
+	}
+	img.SetRGBA(x, y, color.RGBA{
+		R: mix(c.R, dst.R),
+		G: mix(c.G, dst.G),
+		B: mix(c.B, dst.B),
+		A: mix(c.A, dst.A),
+	})
+}
+
+func drawLabel(img *image.RGBA, label string) {
+	face := basicfont.Face7x13
+
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(textColor),
+		Face: face,
+	}
+
+	width := drawer.MeasureString(label)
+	drawer.Dot = fixed.Point26_6{
+		X: (fixed.I(size) - width) / 2,
+		Y: fixed.I((size + face.Ascent - face.Descent) / 2),
+	}
+	drawer.DrawString(label)
+}
+
+// Abbreviate formats value as a short rounded number, e.g. 12, -340 or 15k.
+func Abbreviate(value float64) string {
+	abs := math.Abs(value)
+	sign := ""
+	if value <= -0.5 {
+		sign = "-"
+	}
+
+	switch {
+	case abs < 999.5:
+		return sign + strconv.FormatFloat(abs, 'f', 0, 64)
+	case abs < 999500:
+		return sign + strconv.FormatFloat(abs/1e3, 'f', 0, 64) + "k"
+	default:
+		return sign + strconv.FormatFloat(abs/1e6, 'f', 0, 64) + "M"
+	}
+}
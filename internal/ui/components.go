@@ -1,17 +1,37 @@
 package ui
 
 import (
+	"gioui.org/font"
 	"gioui.org/layout"
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"image"
+	"image/color"
 )
 
+// ErrorColor highlights errors and losses.
+var ErrorColor = color.NRGBA{R: 200, A: 255}
+
+// PnlColor is green for a gain, red for a loss and the default text colour
+// otherwise.
+func PnlColor(th *material.Theme, value float64) color.NRGBA {
+	switch {
+	case value > 0:
+		return gainColor
+	case value < 0:
+		return ErrorColor
+	default:
+		return th.Fg
+	}
+}
+
 type LabeledInput struct {
 	Label       string
 	Editor      *widget.Editor
 	Hint        string
+	Error       string
 	Theme       *material.Theme
 	LabelInset  layout.Inset
 	EditorInset layout.Inset
@@ -41,16 +61,49 @@ func (l LabeledInput) Layout(gtx layout.Context) layout.Dimensions {
 			)
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return l.EditorInset.Layout(gtx,
+			inset := l.EditorInset
+			if l.Error != "" {
+				inset.Bottom = 0
+			}
+			return inset.Layout(gtx,
 				func(gtx layout.Context) layout.Dimensions {
 					ed := material.Editor(l.Theme, l.Editor, l.Hint)
 					return ed.Layout(gtx)
 				},
 			)
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if l.Error == "" {
+				return layout.Dimensions{}
+			}
+			inset := l.EditorInset
+			inset.Top = unit.Dp(4)
+			return inset.Layout(gtx,
+				func(gtx layout.Context) layout.Dimensions {
+					label := material.Caption(l.Theme, l.Error)
+					label.Color = ErrorColor
+					return label.Layout(gtx)
+				},
+			)
+		}),
 	)
 }
 
+func InputWithButton(th *material.Theme, input LabeledInput, button *widget.Clickable, text string) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Spacing:   layout.SpaceBetween,
+			Alignment: layout.Middle,
+		}.Layout(gtx,
+			layout.Flexed(0.75, input.Layout),
+			layout.Flexed(0.25, func(gtx layout.Context) layout.Dimensions {
+				return layout.Center.Layout(gtx, material.Button(th, button, text).Layout)
+			}),
+		)
+	}
+}
+
 func CenteredButton(th *material.Theme, button *widget.Clickable, text string) layout.Widget {
 	return func(gtx layout.Context) layout.Dimensions {
 		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -101,6 +154,46 @@ func StatusText(th *material.Theme, txt string) layout.Widget {
 	}
 }
 
+func WarningText(th *material.Theme, txt string) layout.Widget {
+	if txt == "" {
+		return func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{}
+		}
+	}
+
+	return func(gtx layout.Context) layout.Dimensions {
+		return CommonInsets.Status.Layout(gtx,
+			func(gtx layout.Context) layout.Dimensions {
+				label := material.Body1(th, txt)
+				label.Color = ErrorColor
+				label.Font.Weight = font.Bold
+				label.Alignment = text.Middle
+				return label.Layout(gtx)
+			},
+		)
+	}
+}
+
+func Spinner(th *material.Theme, active bool) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		if !active {
+			return layout.Dimensions{}
+		}
+
+		return CommonInsets.Button.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			size := gtx.Dp(unit.Dp(24))
+			gtx.Constraints = layout.Exact(image.Pt(size, size))
+			return material.Loader(th).Layout(gtx)
+		})
+	}
+}
+
+func CheckBox(th *material.Theme, value *widget.Bool, label string) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		return CommonInsets.Label.Layout(gtx, material.CheckBox(th, value, label).Layout)
+	}
+}
+
 func Spacer(height unit.Dp) layout.Widget {
 	return layout.Spacer{Height: height}.Layout
 }
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package ui
+
+import "gioui.org/app"
+
+// PlacementSupported reports whether KeepOnTop and WindowPosition work on
+// this platform.
+const PlacementSupported = false
+
+// KeepOnTop is only supported on Windows. Elsewhere the window manager
+// decides the stacking and position of the window.
+func KeepOnTop(view app.ViewEvent, x, y int, move bool) {}
+
+// WindowPosition is only supported on Windows.
+func WindowPosition(view app.ViewEvent) (int, int, bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+
+	"gioui.org/app"
+)
+
+// PlacementSupported reports whether KeepOnTop and WindowPosition work on
+// this platform.
+const PlacementSupported = true
+
+var (
+	user32            = syscall.NewLazyDLL("user32.dll")
+	procSetWindowPos  = user32.NewProc("SetWindowPos")
+	procGetWindowRect = user32.NewProc("GetWindowRect")
+)
+
+const (
+	hwndTopmost   = ^uintptr(0) // HWND_TOPMOST, (HWND)-1
+	swpNoSize     = 0x0001
+	swpNoMove     = 0x0002
+	swpNoActive   = 0x0010
+	swpShowWindow = 0x0040
+)
+
+// KeepOnTop keeps the window above all others and, when move is set, places
+// its top left corner at x, y in screen pixels.
+func KeepOnTop(view app.ViewEvent, x, y int, move bool) {
+	e, ok := view.(app.Win32ViewEvent)
+	if !ok || e.HWND == 0 {
+		return
+	}
+
+	flags := uintptr(swpNoSize | swpNoActive | swpShowWindow)
+	if !move {
+		flags |= swpNoMove
+	}
+
+	procSetWindowPos.Call(e.HWND, hwndTopmost, uintptr(x), uintptr(y), 0, 0, flags)
+}
+
+// WindowPosition returns the top left corner of the window in screen pixels.
+func WindowPosition(view app.ViewEvent) (int, int, bool) {
+	e, ok := view.(app.Win32ViewEvent)
+	if !ok || e.HWND == 0 {
+		return 0, 0, false
+	}
+
+	var rect struct{ Left, Top, Right, Bottom int32 }
+	if r, _, _ := procGetWindowRect.Call(e.HWND, uintptr(unsafe.Pointer(&rect))); r == 0 {
+		return 0, 0, false
+	}
+
+	return int(rect.Left), int(rect.Top), true
+}
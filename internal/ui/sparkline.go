@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+)
+
+var (
+	gainColor = color.NRGBA{G: 140, A: 255}
+	axisColor = color.NRGBA{R: 180, G: 180, B: 180, A: 255}
+)
+
+// Sparkline draws values as a line over the available width. A grey line
+// marks zero; the line is green when the last value is positive and red
+// otherwise.
+func Sparkline(values []float64, height unit.Dp) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		size := image.Pt(gtx.Constraints.Max.X, gtx.Dp(height))
+		if len(values) < 2 || size.X <= 0 {
+			return layout.Dimensions{Size: size}
+		}
+
+		low, high := 0.0, 0.0
+		for _, v := range values {
+			low, high = min(low, v), max(high, v)
+		}
+		if high == low {
+			high = low + 1
+		}
+
+		point := func(i int, v float64) f32.Point {
+			x := float32(i) / float32(len(values)-1) * float32(size.X)
+			y := float32((high - v) / (high - low) * float64(size.Y))
+			return f32.Pt(x, y)
+		}
+
+		var axis clip.Path
+		axis.Begin(gtx.Ops)
+		axis.MoveTo(point(0, 0))
+		axis.LineTo(point(len(values)-1, 0))
+		paint.FillShape(gtx.Ops, axisColor, clip.Stroke{Path: axis.End(), Width: 1}.Op())
+
+		var line clip.Path
+		line.Begin(gtx.Ops)
+		line.MoveTo(point(0, values[0]))
+		for i, v := range values[1:] {
+			line.LineTo(point(i+1, v))
+		}
+
+		lineColor := ErrorColor
+		if values[len(values)-1] > 0 {
+			lineColor = gainColor
+		}
+		paint.FillShape(gtx.Ops, lineColor, clip.Stroke{Path: line.End(), Width: float32(gtx.Dp(2))}.Op())
+
+		return layout.Dimensions{Size: size}
+	}
+}
@@ -11,6 +11,12 @@ import (
 type WindowHandler func(gtx layout.Context, th interface{}, closeRequested chan bool) layout.Dimensions
 
 func RunWindow(w *app.Window, handler WindowHandler, theme interface{}) error {
+	return RunWindowWithView(w, handler, theme, nil)
+}
+
+// RunWindowWithView is RunWindow that also passes the platform view of the
+// window to onView once it is created.
+func RunWindowWithView(w *app.Window, handler WindowHandler, theme interface{}, onView func(app.ViewEvent)) error {
 	var ops op.Ops
 	closeRequested := make(chan bool, 1)
 
@@ -21,6 +27,11 @@ func RunWindow(w *app.Window, handler WindowHandler, theme interface{}) error {
 		case app.DestroyEvent:
 			return e.Err
 
+		case app.ViewEvent:
+			if onView != nil && e.Valid() {
+				onView(e)
+			}
+
 		case app.FrameEvent:
 			gtx := app.NewContext(&ops, e)
 